@@ -35,6 +35,8 @@ package v1alpha1
 
 import (
 	"github.com/operator-framework/operator-lib/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // CopyMethodType defines the methods for creating point-in-time copies of
@@ -61,6 +63,9 @@ const (
 	// ReconciledReasonError indicates an error was encountered while
 	// reconciling the CR
 	ReconciledReasonError status.ConditionReason = "ReconcileError"
+	// ReconciledReasonInvalidSpec indicates the CR requests something that
+	// its data mover does not support
+	ReconciledReasonInvalidSpec status.ConditionReason = "InvalidSpec"
 )
 
 const (
@@ -70,3 +75,146 @@ const (
 	SynchronizingReasonManual  status.ConditionReason = "WaitingForManual"
 	SynchronizingReasonCleanup status.ConditionReason = "CleaningUp"
 )
+
+const (
+	// ConditionSyncFailed is a status condition type that indicates whether
+	// the most recent attempt to synchronize failed. While True, its reason is
+	// the cause of the failure as reported by the data mover (e.g., OOMKilled).
+	ConditionSyncFailed status.ConditionType = "SyncFailed"
+	// SyncFailedReasonSucceeded indicates the most recent synchronization
+	// completed successfully
+	SyncFailedReasonSucceeded status.ConditionReason = "SyncSucceeded"
+)
+
+const (
+	// ConditionRepositoryHealthy is a status condition type that indicates
+	// whether the most recent integrity check of the backup repository found
+	// any errors.
+	ConditionRepositoryHealthy status.ConditionType = "RepositoryHealthy"
+	// RepositoryHealthyReasonCheckPassed indicates the check found no errors
+	RepositoryHealthyReasonCheckPassed status.ConditionReason = "CheckPassed"
+	// RepositoryHealthyReasonCheckFailed indicates the check reported errors
+	RepositoryHealthyReasonCheckFailed status.ConditionReason = "CheckFailed"
+)
+
+const (
+	// ConditionRepositoryLocked is a status condition type that indicates
+	// whether the backup repository is held by a lock that is preventing the
+	// data mover from completing.
+	ConditionRepositoryLocked status.ConditionType = "RepositoryLocked"
+	// RepositoryLockedReasonUnlocked indicates no lock blocked the data mover
+	RepositoryLockedReasonUnlocked status.ConditionReason = "Unlocked"
+	// RepositoryLockedReasonStaleLockRemoved indicates that stale locks were
+	// removed from the repository
+	RepositoryLockedReasonStaleLockRemoved status.ConditionReason = "StaleLockRemoved"
+	// RepositoryLockedReasonLockHeld indicates that the data mover failed
+	// while locks it will not remove were present
+	RepositoryLockedReasonLockHeld status.ConditionReason = "LockHeld"
+)
+
+// SyncProgress is the progress of an in-flight synchronization, as reported by
+// the data mover. Values that the mover is unable to determine are omitted.
+type SyncProgress struct {
+	// phase is the mover-specific step of the synchronization that is
+	// currently being performed.
+	//+optional
+	Phase string `json:"phase,omitempty"`
+	// bytesDone is the amount of data, in bytes, that has been processed.
+	//+optional
+	BytesDone *int64 `json:"bytesDone,omitempty"`
+	// bytesTotal is the estimated total amount of data, in bytes, to be
+	// processed.
+	//+optional
+	BytesTotal *int64 `json:"bytesTotal,omitempty"`
+	// filesDone is the number of files that have been processed.
+	//+optional
+	FilesDone *int64 `json:"filesDone,omitempty"`
+	// filesTotal is the estimated total number of files to be processed.
+	//+optional
+	FilesTotal *int64 `json:"filesTotal,omitempty"`
+	// percentDone is the estimated percentage of the synchronization that has
+	// been completed.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=100
+	//+optional
+	PercentDone *int32 `json:"percentDone,omitempty"`
+}
+
+// SyncResult is the outcome of a completed synchronization, as reported by the
+// data mover. Values that the mover is unable to determine are omitted.
+type SyncResult struct {
+	// bytesTransferred is the amount of data, in bytes, that was transferred
+	// (or, for backups, added to the repository).
+	//+optional
+	BytesTransferred *int64 `json:"bytesTransferred,omitempty"`
+	// bytesTotal is the total amount of data, in bytes, that was replicated.
+	//+optional
+	BytesTotal *int64 `json:"bytesTotal,omitempty"`
+	// filesChanged is the number of files that were created or modified.
+	//+optional
+	FilesChanged *int64 `json:"filesChanged,omitempty"`
+	// filesTotal is the total number of files that were replicated.
+	//+optional
+	FilesTotal *int64 `json:"filesTotal,omitempty"`
+	// snapshotID is the identifier of the backup snapshot that was created or
+	// restored.
+	//+optional
+	SnapshotID string `json:"snapshotID,omitempty"`
+	// details contains additional, mover-specific information about the
+	// synchronization.
+	//+optional
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// MoverConfig contains settings that are applied to the pods of the data mover
+// Jobs.
+type MoverConfig struct {
+	// resources are the compute resources that are requested by the data mover
+	// container.
+	//+optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// nodeSelector restricts the nodes that the data mover pods may be
+	// scheduled onto.
+	//+optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// tolerations permit the data mover pods to be scheduled onto nodes with
+	// matching taints.
+	//+optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// affinity defines the scheduling constraints of the data mover pods.
+	//+optional
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// priorityClassName is the name of the PriorityClass to use for the data
+	// mover pods.
+	//+optional
+	PriorityClassName *string `json:"priorityClassName,omitempty"`
+}
+
+// RetryPolicy controls how a data mover is retried after it fails.
+type RetryPolicy struct {
+	// maxAttempts is the number of times the data mover may fail during a
+	// synchronization iteration before the iteration is abandoned. The next
+	// iteration starts with the next trigger. If unset, the data mover is
+	// retried until it succeeds.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	MaxAttempts *int32 `json:"maxAttempts,omitempty"`
+	// backoff is the amount of time to wait after a failure before the data
+	// mover is retried. If unset, it is retried immediately.
+	//+optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
+// CustomCASpec references a custom CA certificate bundle, held in either a
+// Secret or a ConfigMap in the same namespace, that is trusted by the data
+// mover.
+type CustomCASpec struct {
+	// secretName is the name of a Secret that contains the CA bundle.
+	//+optional
+	SecretName string `json:"secretName,omitempty"`
+	// configMapName is the name of a ConfigMap that contains the CA bundle.
+	//+optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// key is the key within the Secret or ConfigMap that holds the CA bundle.
+	Key string `json:"key"`
+}
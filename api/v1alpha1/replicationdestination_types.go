@@ -96,7 +96,9 @@ type ReplicationDestinationRsyncSpec struct {
 	// SSH connections.
 	//+optional
 	ServiceType *v1.ServiceType `json:"serviceType,omitempty"`
-	// address is the remote address to connect to for replication.
+	// address is the remote address to connect to for replication. If provided,
+	// the destination connects to the source and pulls the data instead of
+	// waiting for the source to connect.
 	//+optional
 	Address *string `json:"address,omitempty"`
 	// port is the SSH port to connect to for replication. Defaults to 22.
@@ -104,10 +106,14 @@ type ReplicationDestinationRsyncSpec struct {
 	//+kubebuilder:validation:Maximum=65535
 	//+optional
 	Port *int32 `json:"port,omitempty"`
-	// path is the remote path to rsync from. Defaults to "/"
+	// path is the remote path to rsync from. When no address is provided, it
+	// is the directory within the destination volume that incoming transfers
+	// are restricted to. Defaults to "/"
 	//+optional
 	Path *string `json:"path,omitempty"`
-	// sshUser is the username for outgoing SSH connections. Defaults to "root".
+	// sshUser is the username for outgoing SSH connections. When no address is
+	// provided, it is the account that incoming connections are accepted on.
+	// Defaults to "root".
 	//+optional
 	SSHUser *string `json:"sshUser,omitempty"`
 }
@@ -158,6 +164,13 @@ type ReplicationDestinationSpec struct {
 	// paused can be used to temporarily stop replication. Defaults to "false".
 	//+optional
 	Paused bool `json:"paused,omitempty"`
+	// moverConfig contains settings that are applied to the pods of the data
+	// mover Jobs, such as resource requirements and scheduling constraints.
+	//+optional
+	MoverConfig *MoverConfig `json:"moverConfig,omitempty"`
+	// retryPolicy controls how the data mover is retried after it fails.
+	//+optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
 }
 
 type ReplicationDestinationRsyncStatus struct {
@@ -177,11 +190,58 @@ type ReplicationDestinationRsyncStatus struct {
 	Port *int32 `json:"port,omitempty"`
 }
 
+// ResticRestoreMode determines how a restore treats the existing contents of
+// the destination volume.
+//+kubebuilder:validation:Enum=Overlay;Mirror
+type ResticRestoreMode string
+
+const (
+	// RestoreModeOverlay restores the snapshot on top of the volume's existing
+	// contents, leaving any other files in place.
+	RestoreModeOverlay ResticRestoreMode = "Overlay"
+	// RestoreModeMirror removes the files that are not part of the snapshot
+	// so that the volume becomes an exact copy of it.
+	RestoreModeMirror ResticRestoreMode = "Mirror"
+)
+
 // ReplicationDestinationResticSpec defines the field for restic in replicationDestination.
 type ReplicationDestinationResticSpec struct {
 	ReplicationDestinationVolumeOptions `json:",inline"`
 	// Repository is the secret name containing repository info
 	Repository string `json:"repository,omitempty"`
+	// repositoryPath is appended to the RESTIC_REPOSITORY from the Secret to
+	// form the location of the repository. This allows a single Secret to
+	// provide a base URL for many ReplicationDestinations. The placeholders
+	// {{namespace}} and {{name}} are replaced by the namespace and name of the
+	// ReplicationDestination.
+	//+optional
+	RepositoryPath *string `json:"repositoryPath,omitempty"`
+	// repositoryPVC is the name of a PVC (in the same namespace) that holds a
+	// Restic repository on its filesystem, for use when there is no object
+	// store. It is mounted into the mover, and the repository Secret then only
+	// needs to provide RESTIC_PASSWORD. The repositoryPath, if any, selects a
+	// directory within the volume. Unless the volume is ReadWriteMany, the
+	// mover is pinned to the node where a pod using the volume is running.
+	//+kubebuilder:validation:MinLength=1
+	//+optional
+	RepositoryPVC *string `json:"repositoryPVC,omitempty"`
+	// repositoryServer selects a repository on a ResticRepositoryServer that
+	// is managed by Scribe. It is used instead of a repository Secret: the
+	// credentials of the repository and its password are generated when it is
+	// first used.
+	//+optional
+	RepositoryServer *ResticRepositoryServerReference `json:"repositoryServer,omitempty"`
+	// customCA is a CA bundle to trust when connecting to the repository
+	// (e.g., an object store whose certificate is signed by an internal CA).
+	//+optional
+	CustomCA *CustomCASpec `json:"customCA,omitempty"`
+	// staleLockAge enables the removal of repository locks that were left
+	// behind by data movers that did not exit cleanly (e.g., due to being
+	// OOM-killed). If all of the repository's locks are older than this, they
+	// are removed before the data mover starts. It must be at least 30m. By
+	// default, locks are never removed automatically.
+	//+optional
+	StaleLockAge *metav1.Duration `json:"staleLockAge,omitempty"`
 	// cacheCapacity can be used to set the size of the restic metadata cache volume
 	//+optional
 	CacheCapacity *resource.Quantity `json:"cacheCapacity,omitempty"`
@@ -192,6 +252,62 @@ type ReplicationDestinationResticSpec struct {
 	// accessModes can be used to set the accessModes of restic metadata cache volume
 	//+optional
 	CacheAccessModes []v1.PersistentVolumeAccessMode `json:"cacheAccessModes,omitempty"`
+	// cacheMode determines how the restic metadata cache volume is
+	// provisioned: as a PVC that persists between synchronizations
+	// (Persistent, the default), as an ephemeral volume of the mover Pod
+	// (Ephemeral), or as an emptyDir limited to cacheCapacity (EmptyDir).
+	//+optional
+	CacheMode *ResticCacheMode `json:"cacheMode,omitempty"`
+	// restoreAsOf selects the newest snapshot taken at or before this time to
+	// be restored. If neither restoreAsOf nor snapshotID is provided, the
+	// latest snapshot is restored.
+	//+optional
+	RestoreAsOf *metav1.Time `json:"restoreAsOf,omitempty"`
+	// snapshotID is the ID of the snapshot to be restored. It may not be
+	// combined with restoreAsOf.
+	//+optional
+	SnapshotID *string `json:"snapshotID,omitempty"`
+	// restoreMode is either Overlay (the default), which restores the snapshot
+	// over the existing contents of the volume, or Mirror, which also deletes
+	// any files that are not in the snapshot. To protect data that Scribe
+	// didn't create, Mirror may only be used with a destinationPVC that is
+	// annotated with scribe.backube/restic-allow-mirror: "true".
+	//+optional
+	RestoreMode *ResticRestoreMode `json:"restoreMode,omitempty"`
+	// stream restores a snapshot that was taken from a stream. Its command,
+	// if any, receives the stream on its standard input (e.g., psql), and the
+	// volume is mounted into its container at /data. Otherwise, the stream is
+	// written to the file stream.filename of the volume.
+	//+optional
+	Stream *ResticStreamSpec `json:"stream,omitempty"`
+	// hostname limits the snapshots that may be restored to those recorded
+	// with this host name (i.e., those of a particular ReplicationSource). It
+	// must be set if the repository is shared by several sources, since a
+	// restore without it fails when the snapshots come from more than one
+	// host.
+	//+kubebuilder:validation:MinLength=1
+	//+optional
+	Hostname *string `json:"hostname,omitempty"`
+	// tags limits the snapshots that may be restored to those having all of
+	// these tags.
+	//+optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ReplicationDestinationResticStatus defines the field for restic in
+// ReplicationDestinationStatus
+type ReplicationDestinationResticStatus struct {
+	// repository is the location of the repository (with any credentials
+	// removed) that was used by the most recent synchronization.
+	//+optional
+	Repository string `json:"repository,omitempty"`
+	// restoredSnapshot is the ID of the snapshot that was restored by the most
+	// recent synchronization.
+	//+optional
+	RestoredSnapshot string `json:"restoredSnapshot,omitempty"`
+	// restoredSnapshotTime is the time that the restored snapshot was taken.
+	//+optional
+	RestoredSnapshotTime *metav1.Time `json:"restoredSnapshotTime,omitempty"`
 }
 
 // ReplicationDestinationStatus defines the observed state of ReplicationDestination
@@ -203,6 +319,9 @@ type ReplicationDestinationStatus struct {
 	// update.
 	//+optional
 	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+	// lastSyncStartTime is the time the most recent synchronization started.
+	//+optional
+	LastSyncStartTime *metav1.Time `json:"lastSyncStartTime,omitempty"`
 	// nextSyncTime is the time when the next volume synchronization is
 	// scheduled to start (for schedule-based synchronization).
 	//+optional
@@ -210,6 +329,23 @@ type ReplicationDestinationStatus struct {
 	// lastManualSync is set to the last spec.trigger.manual when the manual sync is done.
 	//+optional
 	LastManualSync string `json:"lastManualSync,omitempty"`
+	// lastSyncResult is the outcome of the most recent successful
+	// synchronization, as reported by the data mover.
+	//+optional
+	LastSyncResult *SyncResult `json:"lastSyncResult,omitempty"`
+	// consecutiveFailures is the number of times in a row that the data mover
+	// has failed. It is reset when a synchronization completes or is
+	// abandoned after retryPolicy.maxAttempts failures.
+	//+optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// lastFailureTime is the time of the most recent failure of the data
+	// mover.
+	//+optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+	// progress is the progress of the synchronization that is currently
+	// underway, as reported by the data mover.
+	//+optional
+	Progress *SyncProgress `json:"progress,omitempty"`
 	// latestImage in the object holding the most recent consistent replicated
 	// image.
 	//+optional
@@ -224,6 +360,9 @@ type ReplicationDestinationStatus struct {
 	// conditions represent the latest available observations of the
 	// destination's state.
 	Conditions status.Conditions `json:"conditions,omitempty"`
+	// restic contains status information for Restic-based replication.
+	//+optional
+	Restic *ReplicationDestinationResticStatus `json:"restic,omitempty"`
 }
 
 // ReplicationDestination defines the destination for a replicated volume
@@ -233,6 +372,7 @@ type ReplicationDestinationStatus struct {
 //+kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
 //+kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
 //+kubebuilder:printcolumn:name="Next sync",type="string",format="date-time",JSONPath=`.status.nextSyncTime`
+//+kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=`.status.progress.percentDone`
 type ReplicationDestination struct {
 	metav1.TypeMeta `json:",inline"`
 	//+optional
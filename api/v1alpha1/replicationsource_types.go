@@ -103,7 +103,9 @@ type ReplicationSourceRsyncSpec struct {
 	// SSH connections.
 	//+optional
 	ServiceType *v1.ServiceType `json:"serviceType,omitempty"`
-	// address is the remote address to connect to for replication.
+	// address is the remote address to connect to for replication. If not
+	// provided, the source waits for the destination to connect and pull the
+	// data instead.
 	//+optional
 	Address *string `json:"address,omitempty"`
 	// port is the SSH port to connect to for replication. Defaults to 22.
@@ -111,10 +113,14 @@ type ReplicationSourceRsyncSpec struct {
 	//+kubebuilder:validation:Maximum=65535
 	//+optional
 	Port *int32 `json:"port,omitempty"`
-	// path is the remote path to rsync to. Defaults to "/"
+	// path is the remote path to rsync to. When no address is provided, it is
+	// the directory within the source volume that the destination is allowed to
+	// read. Defaults to "/"
 	//+optional
 	Path *string `json:"path,omitempty"`
-	// sshUser is the username for outgoing SSH connections. Defaults to "root".
+	// sshUser is the username for outgoing SSH connections. When no address is
+	// provided, it is the account that incoming connections are accepted on.
+	// Defaults to "root".
 	//+optional
 	SSHUser *string `json:"sshUser,omitempty"`
 }
@@ -152,13 +158,109 @@ type ResticRetainPolicy struct {
 	Within *string `json:"within,omitempty"`
 }
 
+// ResticCacheMode determines how the Restic metadata cache volume is
+// provisioned
+//+kubebuilder:validation:Enum=Persistent;Ephemeral;EmptyDir
+type ResticCacheMode string
+
+const (
+	// CacheModePersistent keeps the cache in a PVC that persists between
+	// synchronizations
+	CacheModePersistent ResticCacheMode = "Persistent"
+	// CacheModeEphemeral provisions the cache as a generic ephemeral volume
+	// that is deleted along with the mover Pod
+	CacheModeEphemeral ResticCacheMode = "Ephemeral"
+	// CacheModeEmptyDir keeps the cache in an emptyDir volume of the mover Pod
+	CacheModeEmptyDir ResticCacheMode = "EmptyDir"
+)
+
+// ResticStreamSpec describes a command that runs alongside the Restic data
+// mover and whose data is streamed through the repository instead of the
+// contents of a volume (e.g., a database dump).
+type ResticStreamSpec struct {
+	// image is the container image in which the command runs. It is required
+	// whenever a command is given.
+	//+optional
+	Image string `json:"image,omitempty"`
+	// command is run in its own container of the mover Pod, without a shell.
+	// On a ReplicationSource, its standard output is backed up. On a
+	// ReplicationDestination, the restored stream is provided on its standard
+	// input. If a ReplicationDestination has no command, the stream is
+	// restored to a file of the volume instead. The container doesn't receive
+	// the token of the mover's ServiceAccount.
+	//+optional
+	Command []string `json:"command,omitempty"`
+	// resources are the compute resources that are requested by the command's
+	// container. The resources of the moverConfig only apply to the Restic
+	// container.
+	//+optional
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// filename is the name under which the stream is stored in each snapshot.
+	// On a ReplicationDestination without a command, it is also the name of
+	// the file to which the stream is restored. Defaults to "stdin".
+	//+kubebuilder:validation:Pattern=`^[^/]+$`
+	//+optional
+	Filename *string `json:"filename,omitempty"`
+}
+
 // ReplicationSourceResticSpec defines the field for restic in replicationSource.
 type ReplicationSourceResticSpec struct {
 	ReplicationSourceVolumeOptions `json:",inline"`
 	// PruneIntervalDays define how often to prune the repository
 	PruneIntervalDays *int32 `json:"pruneIntervalDays,omitempty"`
+	// pruneSchedule is a cronspec (https://en.wikipedia.org/wiki/Cron#Overview)
+	// for pruning the repository in a Job that is separate from the backups.
+	// A prune is never started while a backup to the same repository is
+	// running, and vice versa. When it is set, pruneIntervalDays is ignored.
+	//+kubebuilder:validation:Pattern=`^(\d+|\*)(/\d+)?(\s+(\d+|\*)(/\d+)?){4}$`
+	//+optional
+	PruneSchedule *string `json:"pruneSchedule,omitempty"`
+	// checkIntervalDays defines how often to check the integrity of the
+	// repository. Checks are not performed if it is omitted.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	CheckIntervalDays *int32 `json:"checkIntervalDays,omitempty"`
+	// readDataSubset is the portion of the repository's data to read and
+	// verify during each check, either as a fraction ("n/t") or a percentage
+	// ("x%"). By default, only the repository's structure is verified.
+	//+kubebuilder:validation:Pattern=`^([0-9]+/[0-9]+|[0-9]+(\.[0-9]+)?%)$`
+	//+optional
+	ReadDataSubset *string `json:"readDataSubset,omitempty"`
 	// Repository is the secret name containing repository info
 	Repository string `json:"repository,omitempty"`
+	// repositoryPath is appended to the RESTIC_REPOSITORY from the Secret to
+	// form the location of the repository. This allows a single Secret to
+	// provide a base URL for many ReplicationSources. The placeholders
+	// {{namespace}} and {{name}} are replaced by the namespace and name of the
+	// ReplicationSource.
+	//+optional
+	RepositoryPath *string `json:"repositoryPath,omitempty"`
+	// repositoryPVC is the name of a PVC (in the same namespace) that holds a
+	// Restic repository on its filesystem, for use when there is no object
+	// store. It is mounted into the mover, and the repository Secret then only
+	// needs to provide RESTIC_PASSWORD. The repositoryPath, if any, selects a
+	// directory within the volume. Unless the volume is ReadWriteMany, the
+	// mover is pinned to the node where a pod using the volume is running.
+	//+kubebuilder:validation:MinLength=1
+	//+optional
+	RepositoryPVC *string `json:"repositoryPVC,omitempty"`
+	// repositoryServer selects a repository on a ResticRepositoryServer that
+	// is managed by Scribe. It is used instead of a repository Secret: the
+	// credentials of the repository and its password are generated when it is
+	// first used.
+	//+optional
+	RepositoryServer *ResticRepositoryServerReference `json:"repositoryServer,omitempty"`
+	// customCA is a CA bundle to trust when connecting to the repository
+	// (e.g., an object store whose certificate is signed by an internal CA).
+	//+optional
+	CustomCA *CustomCASpec `json:"customCA,omitempty"`
+	// staleLockAge enables the removal of repository locks that were left
+	// behind by data movers that did not exit cleanly (e.g., due to being
+	// OOM-killed). If all of the repository's locks are older than this, they
+	// are removed before the data mover starts. It must be at least 30m. By
+	// default, locks are never removed automatically.
+	//+optional
+	StaleLockAge *metav1.Duration `json:"staleLockAge,omitempty"`
 	// ResticRetainPolicy define the retain policy
 	//+optional
 	Retain *ResticRetainPolicy `json:"retain,omitempty"`
@@ -172,6 +274,106 @@ type ReplicationSourceResticSpec struct {
 	// accessModes can be used to set the accessModes of restic metadata cache volume
 	//+optional
 	CacheAccessModes []v1.PersistentVolumeAccessMode `json:"cacheAccessModes,omitempty"`
+	// cacheMode determines how the restic metadata cache volume is
+	// provisioned: as a PVC that persists between synchronizations
+	// (Persistent, the default), as an ephemeral volume of the mover Pod
+	// (Ephemeral), or as an emptyDir limited to cacheCapacity (EmptyDir).
+	//+optional
+	CacheMode *ResticCacheMode `json:"cacheMode,omitempty"`
+	// include is a list of the paths (relative to the root of the volume) to
+	// back up. Glob patterns are permitted. If omitted, the entire volume is
+	// backed up.
+	//+optional
+	Include []string `json:"include,omitempty"`
+	// exclude is a list of patterns for files and directories that should not
+	// be backed up. Patterns beginning with "/" are anchored at the root of the
+	// volume.
+	//+optional
+	Exclude []string `json:"exclude,omitempty"`
+	// excludeCaches skips the contents of directories that are marked as
+	// caches via a CACHEDIR.TAG file.
+	//+optional
+	ExcludeCaches bool `json:"excludeCaches,omitempty"`
+	// excludeIfPresent is a list of file names that cause the directory
+	// containing them to be skipped.
+	//+optional
+	ExcludeIfPresent []string `json:"excludeIfPresent,omitempty"`
+	// stream backs up the output of a command (e.g., pg_dump) instead of the
+	// contents of the sourcePVC. The sourcePVC may then be omitted; if it is
+	// given, it is mounted into the command's container at /data. The
+	// include and exclude filters don't apply to a stream.
+	//+optional
+	Stream *ResticStreamSpec `json:"stream,omitempty"`
+	// hostname is the host name that is recorded in each snapshot. Along with
+	// the tags, it identifies the snapshots that belong to this source, which
+	// allows several sources to share a repository. It defaults to
+	// "<namespace>/<name>" of the ReplicationSource.
+	//+kubebuilder:validation:MinLength=1
+	//+optional
+	Hostname *string `json:"hostname,omitempty"`
+	// tags are added to each snapshot. Tags may not contain commas.
+	//+optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ResticKeyRotationStatus describes the rotation of a Restic repository's
+// password to the RESTIC_NEW_PASSWORD of the repository Secret.
+type ResticKeyRotationStatus struct {
+	// phase is "InProgress", "Completed", or "Retired". Once the rotation has
+	// completed, the repository accepts the new password, so it can be moved
+	// to RESTIC_PASSWORD. The old password remains valid until its key is
+	// retired via the scribe.backube/restic-retire-key annotation.
+	Phase string `json:"phase"`
+	// keyID is the ID of the repository key for the new password.
+	//+optional
+	KeyID string `json:"keyID,omitempty"`
+	// oldKeyID is the ID of the repository key for the old password while it
+	// is still accepted by the repository.
+	//+optional
+	OldKeyID string `json:"oldKeyID,omitempty"`
+	// completionTime is when the rotation completed.
+	//+optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// secretResourceVersion is the resourceVersion of the repository Secret
+	// whose new password was installed. The key isn't rotated again until the
+	// Secret changes.
+	//+optional
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
+}
+
+// ResticSnapshot describes a backup that is retained in a Restic repository
+type ResticSnapshot struct {
+	// id is the ID of the snapshot.
+	ID string `json:"id"`
+	// time is when the snapshot was taken.
+	Time metav1.Time `json:"time"`
+	// size is the amount of data, in bytes, that was backed up. It is only
+	// known for snapshots created while Scribe recorded their sizes.
+	//+optional
+	Size *int64 `json:"size,omitempty"`
+	// tags are the tags of the snapshot.
+	//+optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ResticPruneStatus describes the prune Job that runs on the pruneSchedule
+type ResticPruneStatus struct {
+	// nextPruneTime is when the next prune is scheduled to start.
+	//+optional
+	NextPruneTime *metav1.Time `json:"nextPruneTime,omitempty"`
+	// lastPruneStartTime is when the most recent prune started.
+	//+optional
+	LastPruneStartTime *metav1.Time `json:"lastPruneStartTime,omitempty"`
+	// lastPruneDuration is how long the most recent prune took.
+	//+optional
+	LastPruneDuration *metav1.Duration `json:"lastPruneDuration,omitempty"`
+	// lastPruneResult is the outcome of the most recent prune, either
+	// "Succeeded" or "Failed".
+	//+optional
+	LastPruneResult string `json:"lastPruneResult,omitempty"`
+	// message describes why the most recent prune failed.
+	//+optional
+	Message string `json:"message,omitempty"`
 }
 
 //ReplicationSourceResticStatus defines the field for ReplicationSourceStatus in ReplicationSourceStatus
@@ -179,6 +381,29 @@ type ReplicationSourceResticStatus struct {
 	// lastPruned in the object holding the time of last pruned
 	//+optional
 	LastPruned *metav1.Time `json:"lastPruned,omitempty"`
+	// lastChecked is the time of the last repository integrity check
+	//+optional
+	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+	// lastCheckResult is the outcome of the last repository integrity check,
+	// either "Passed" or "Failed"
+	//+optional
+	LastCheckResult string `json:"lastCheckResult,omitempty"`
+	// repository is the location of the repository (with any credentials
+	// removed) that was used by the most recent synchronization.
+	//+optional
+	Repository string `json:"repository,omitempty"`
+	// keyRotation is the state of the most recent rotation of the
+	// repository's password.
+	//+optional
+	KeyRotation *ResticKeyRotationStatus `json:"keyRotation,omitempty"`
+	// snapshots are the most recent snapshots that are retained in the
+	// repository, newest first, as of the last backup. The list is limited to
+	// 20 entries.
+	//+optional
+	Snapshots []ResticSnapshot `json:"snapshots,omitempty"`
+	// prune is the state of the prune Job when a pruneSchedule is set.
+	//+optional
+	Prune *ResticPruneStatus `json:"prune,omitempty"`
 }
 
 // ReplicationSourceSpec defines the desired state of ReplicationSource
@@ -205,6 +430,13 @@ type ReplicationSourceSpec struct {
 	// paused can be used to temporarily stop replication. Defaults to "false".
 	//+optional
 	Paused bool `json:"paused,omitempty"`
+	// moverConfig contains settings that are applied to the pods of the data
+	// mover Jobs, such as resource requirements and scheduling constraints.
+	//+optional
+	MoverConfig *MoverConfig `json:"moverConfig,omitempty"`
+	// retryPolicy controls how the data mover is retried after it fails.
+	//+optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
 }
 
 type ReplicationSourceRsyncStatus struct {
@@ -233,6 +465,9 @@ type ReplicationSourceStatus struct {
 	// update.
 	//+optional
 	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+	// lastSyncStartTime is the time the most recent synchronization started.
+	//+optional
+	LastSyncStartTime *metav1.Time `json:"lastSyncStartTime,omitempty"`
 	// nextSyncTime is the time when the next volume synchronization is
 	// scheduled to start (for schedule-based synchronization).
 	//+optional
@@ -240,6 +475,23 @@ type ReplicationSourceStatus struct {
 	// lastManualSync is set to the last spec.trigger.manual when the manual sync is done.
 	//+optional
 	LastManualSync string `json:"lastManualSync,omitempty"`
+	// lastSyncResult is the outcome of the most recent successful
+	// synchronization, as reported by the data mover.
+	//+optional
+	LastSyncResult *SyncResult `json:"lastSyncResult,omitempty"`
+	// consecutiveFailures is the number of times in a row that the data mover
+	// has failed. It is reset when a synchronization completes or is
+	// abandoned after retryPolicy.maxAttempts failures.
+	//+optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// lastFailureTime is the time of the most recent failure of the data
+	// mover.
+	//+optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+	// progress is the progress of the synchronization that is currently
+	// underway, as reported by the data mover.
+	//+optional
+	Progress *SyncProgress `json:"progress,omitempty"`
 	// rsync contains status information for Rsync-based replication.
 	Rsync *ReplicationSourceRsyncStatus `json:"rsync,omitempty"`
 	// external contains provider-specific status information. For more details,
@@ -262,6 +514,7 @@ type ReplicationSourceStatus struct {
 //+kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
 //+kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
 //+kubebuilder:printcolumn:name="Next sync",type="string",format="date-time",JSONPath=`.status.nextSyncTime`
+//+kubebuilder:printcolumn:name="Progress",type="integer",JSONPath=`.status.progress.percentDone`
 type ReplicationSource struct {
 	metav1.TypeMeta `json:",inline"`
 	//+optional
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/operator-framework/operator-lib/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionServerAvailable is a status condition type that indicates
+	// whether the REST server of a ResticRepositoryServer is able to accept
+	// connections.
+	ConditionServerAvailable status.ConditionType = "Available"
+	// ServerAvailableReasonAvailable indicates the server is running
+	ServerAvailableReasonAvailable status.ConditionReason = "ServerAvailable"
+	// ServerAvailableReasonUnavailable indicates the server is not (yet)
+	// running
+	ServerAvailableReasonUnavailable status.ConditionReason = "ServerUnavailable"
+)
+
+// ResticRepositoryServerSpec defines the desired state of
+// ResticRepositoryServer
+type ResticRepositoryServerSpec struct {
+	// capacity is the size of the volume that holds the repositories. The
+	// volume is intentionally not owned by the server, so it is retained when
+	// the server is deleted.
+	Capacity resource.Quantity `json:"capacity"`
+	// storageClassName can be used to set the StorageClass of the volume that
+	// holds the repositories.
+	//+optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// accessModes can be used to set the accessModes of the volume that holds
+	// the repositories. Defaults to ReadWriteOnce.
+	//+optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// appendOnly prevents the clients of the server from removing or
+	// modifying the data in their repositories. Backups can still be added,
+	// but the repositories are never pruned, and snapshots can't be deleted
+	// (e.g., by ransomware that has obtained the credentials). Defaults to
+	// "false".
+	//+optional
+	AppendOnly bool `json:"appendOnly,omitempty"`
+	// paused can be used to temporarily stop the server. Defaults to "false".
+	//+optional
+	Paused bool `json:"paused,omitempty"`
+	// podConfig contains settings that are applied to the pod of the server,
+	// such as resource requirements and scheduling constraints.
+	//+optional
+	PodConfig *MoverConfig `json:"podConfig,omitempty"`
+}
+
+// ResticRepositoryServerStatus defines the observed state of
+// ResticRepositoryServer
+type ResticRepositoryServerStatus struct {
+	// address is the URL at which the server can be reached from within the
+	// cluster.
+	//+optional
+	Address string `json:"address,omitempty"`
+	// repositories is the list of repositories that have credentials on the
+	// server.
+	//+optional
+	Repositories []string `json:"repositories,omitempty"`
+	// conditions represent the latest available observations of the
+	// server's state.
+	//+optional
+	Conditions status.Conditions `json:"conditions,omitempty"`
+}
+
+// ResticRepositoryServerReference selects a repository that is hosted by a
+// ResticRepositoryServer
+type ResticRepositoryServerReference struct {
+	// name is the name of the ResticRepositoryServer. It must be in the same
+	// namespace as the ReplicationSource or ReplicationDestination.
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// repository is the name of the repository on the server. Each repository
+	// has its own credentials, which are generated by Scribe and stored in
+	// the Secret scribe-rest-<name>-<repository>. Defaults to the name of the
+	// ReplicationSource or ReplicationDestination.
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`
+	//+kubebuilder:validation:MaxLength=63
+	//+optional
+	Repository *string `json:"repository,omitempty"`
+}
+
+// ResticRepositoryServer is an on-cluster Restic REST server that stores the
+// repositories of Restic-based ReplicationSources
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Address",type="string",JSONPath=`.status.address`
+//+kubebuilder:printcolumn:name="Append only",type="boolean",JSONPath=`.spec.appendOnly`
+//+kubebuilder:printcolumn:name="Capacity",type="string",JSONPath=`.spec.capacity`
+type ResticRepositoryServer struct {
+	metav1.TypeMeta `json:",inline"`
+	//+optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// spec is the desired state of the ResticRepositoryServer.
+	Spec ResticRepositoryServerSpec `json:"spec,omitempty"`
+	// status is the observed state of the ResticRepositoryServer as
+	// determined by the controller.
+	//+optional
+	Status *ResticRepositoryServerStatus `json:"status,omitempty"`
+}
+
+// ResticRepositoryServerList contains a list of ResticRepositoryServer
+//+kubebuilder:object:root=true
+type ResticRepositoryServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResticRepositoryServer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResticRepositoryServer{}, &ResticRepositoryServerList{})
+}
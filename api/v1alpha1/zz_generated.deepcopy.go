@@ -44,6 +44,65 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomCASpec) DeepCopyInto(out *CustomCASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomCASpec.
+func (in *CustomCASpec) DeepCopy() *CustomCASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomCASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MoverConfig) DeepCopyInto(out *MoverConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MoverConfig.
+func (in *MoverConfig) DeepCopy() *MoverConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MoverConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationDestination) DeepCopyInto(out *ReplicationDestination) {
 	*out = *in
@@ -164,6 +223,31 @@ func (in *ReplicationDestinationRcloneSpec) DeepCopy() *ReplicationDestinationRc
 func (in *ReplicationDestinationResticSpec) DeepCopyInto(out *ReplicationDestinationResticSpec) {
 	*out = *in
 	in.ReplicationDestinationVolumeOptions.DeepCopyInto(&out.ReplicationDestinationVolumeOptions)
+	if in.RepositoryPath != nil {
+		in, out := &in.RepositoryPath, &out.RepositoryPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.RepositoryPVC != nil {
+		in, out := &in.RepositoryPVC, &out.RepositoryPVC
+		*out = new(string)
+		**out = **in
+	}
+	if in.RepositoryServer != nil {
+		in, out := &in.RepositoryServer, &out.RepositoryServer
+		*out = new(ResticRepositoryServerReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomCA != nil {
+		in, out := &in.CustomCA, &out.CustomCA
+		*out = new(CustomCASpec)
+		**out = **in
+	}
+	if in.StaleLockAge != nil {
+		in, out := &in.StaleLockAge, &out.StaleLockAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.CacheCapacity != nil {
 		in, out := &in.CacheCapacity, &out.CacheCapacity
 		x := (*in).DeepCopy()
@@ -179,6 +263,40 @@ func (in *ReplicationDestinationResticSpec) DeepCopyInto(out *ReplicationDestina
 		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
+	if in.CacheMode != nil {
+		in, out := &in.CacheMode, &out.CacheMode
+		*out = new(ResticCacheMode)
+		**out = **in
+	}
+	if in.RestoreAsOf != nil {
+		in, out := &in.RestoreAsOf, &out.RestoreAsOf
+		*out = (*in).DeepCopy()
+	}
+	if in.SnapshotID != nil {
+		in, out := &in.SnapshotID, &out.SnapshotID
+		*out = new(string)
+		**out = **in
+	}
+	if in.RestoreMode != nil {
+		in, out := &in.RestoreMode, &out.RestoreMode
+		*out = new(ResticRestoreMode)
+		**out = **in
+	}
+	if in.Stream != nil {
+		in, out := &in.Stream, &out.Stream
+		*out = new(ResticStreamSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationResticSpec.
@@ -191,6 +309,25 @@ func (in *ReplicationDestinationResticSpec) DeepCopy() *ReplicationDestinationRe
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationDestinationResticStatus) DeepCopyInto(out *ReplicationDestinationResticStatus) {
+	*out = *in
+	if in.RestoredSnapshotTime != nil {
+		in, out := &in.RestoredSnapshotTime, &out.RestoredSnapshotTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationResticStatus.
+func (in *ReplicationDestinationResticStatus) DeepCopy() *ReplicationDestinationResticStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationDestinationResticStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationDestinationRsyncSpec) DeepCopyInto(out *ReplicationDestinationRsyncSpec) {
 	*out = *in
@@ -295,6 +432,16 @@ func (in *ReplicationDestinationSpec) DeepCopyInto(out *ReplicationDestinationSp
 		*out = new(ReplicationDestinationExternalSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MoverConfig != nil {
+		in, out := &in.MoverConfig, &out.MoverConfig
+		*out = new(MoverConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationSpec.
@@ -319,10 +466,28 @@ func (in *ReplicationDestinationStatus) DeepCopyInto(out *ReplicationDestination
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.LastSyncStartTime != nil {
+		in, out := &in.LastSyncStartTime, &out.LastSyncStartTime
+		*out = (*in).DeepCopy()
+	}
 	if in.NextSyncTime != nil {
 		in, out := &in.NextSyncTime, &out.NextSyncTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSyncResult != nil {
+		in, out := &in.LastSyncResult, &out.LastSyncResult
+		*out = new(SyncResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(SyncProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.LatestImage != nil {
 		in, out := &in.LatestImage, &out.LatestImage
 		*out = new(v1.TypedLocalObjectReference)
@@ -347,6 +512,11 @@ func (in *ReplicationDestinationStatus) DeepCopyInto(out *ReplicationDestination
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Restic != nil {
+		in, out := &in.Restic, &out.Restic
+		*out = new(ReplicationDestinationResticStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationStatus.
@@ -544,6 +714,46 @@ func (in *ReplicationSourceResticSpec) DeepCopyInto(out *ReplicationSourceRestic
 		*out = new(int32)
 		**out = **in
 	}
+	if in.PruneSchedule != nil {
+		in, out := &in.PruneSchedule, &out.PruneSchedule
+		*out = new(string)
+		**out = **in
+	}
+	if in.CheckIntervalDays != nil {
+		in, out := &in.CheckIntervalDays, &out.CheckIntervalDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReadDataSubset != nil {
+		in, out := &in.ReadDataSubset, &out.ReadDataSubset
+		*out = new(string)
+		**out = **in
+	}
+	if in.RepositoryPath != nil {
+		in, out := &in.RepositoryPath, &out.RepositoryPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.RepositoryPVC != nil {
+		in, out := &in.RepositoryPVC, &out.RepositoryPVC
+		*out = new(string)
+		**out = **in
+	}
+	if in.RepositoryServer != nil {
+		in, out := &in.RepositoryServer, &out.RepositoryServer
+		*out = new(ResticRepositoryServerReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomCA != nil {
+		in, out := &in.CustomCA, &out.CustomCA
+		*out = new(CustomCASpec)
+		**out = **in
+	}
+	if in.StaleLockAge != nil {
+		in, out := &in.StaleLockAge, &out.StaleLockAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Retain != nil {
 		in, out := &in.Retain, &out.Retain
 		*out = new(ResticRetainPolicy)
@@ -564,6 +774,41 @@ func (in *ReplicationSourceResticSpec) DeepCopyInto(out *ReplicationSourceRestic
 		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
+	if in.CacheMode != nil {
+		in, out := &in.CacheMode, &out.CacheMode
+		*out = new(ResticCacheMode)
+		**out = **in
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeIfPresent != nil {
+		in, out := &in.ExcludeIfPresent, &out.ExcludeIfPresent
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stream != nil {
+		in, out := &in.Stream, &out.Stream
+		*out = new(ResticStreamSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hostname != nil {
+		in, out := &in.Hostname, &out.Hostname
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceResticSpec.
@@ -583,6 +828,27 @@ func (in *ReplicationSourceResticStatus) DeepCopyInto(out *ReplicationSourceRest
 		in, out := &in.LastPruned, &out.LastPruned
 		*out = (*in).DeepCopy()
 	}
+	if in.LastChecked != nil {
+		in, out := &in.LastChecked, &out.LastChecked
+		*out = (*in).DeepCopy()
+	}
+	if in.KeyRotation != nil {
+		in, out := &in.KeyRotation, &out.KeyRotation
+		*out = new(ResticKeyRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]ResticSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Prune != nil {
+		in, out := &in.Prune, &out.Prune
+		*out = new(ResticPruneStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceResticStatus.
@@ -699,6 +965,16 @@ func (in *ReplicationSourceSpec) DeepCopyInto(out *ReplicationSourceSpec) {
 		*out = new(ReplicationSourceExternalSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MoverConfig != nil {
+		in, out := &in.MoverConfig, &out.MoverConfig
+		*out = new(MoverConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceSpec.
@@ -723,10 +999,28 @@ func (in *ReplicationSourceStatus) DeepCopyInto(out *ReplicationSourceStatus) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.LastSyncStartTime != nil {
+		in, out := &in.LastSyncStartTime, &out.LastSyncStartTime
+		*out = (*in).DeepCopy()
+	}
 	if in.NextSyncTime != nil {
 		in, out := &in.NextSyncTime, &out.NextSyncTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSyncResult != nil {
+		in, out := &in.LastSyncResult, &out.LastSyncResult
+		*out = new(SyncResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(SyncProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Rsync != nil {
 		in, out := &in.Rsync, &out.Rsync
 		*out = new(ReplicationSourceRsyncStatus)
@@ -818,6 +1112,194 @@ func (in *ReplicationSourceVolumeOptions) DeepCopy() *ReplicationSourceVolumeOpt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticKeyRotationStatus) DeepCopyInto(out *ResticKeyRotationStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticKeyRotationStatus.
+func (in *ResticKeyRotationStatus) DeepCopy() *ResticKeyRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticKeyRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticPruneStatus) DeepCopyInto(out *ResticPruneStatus) {
+	*out = *in
+	if in.NextPruneTime != nil {
+		in, out := &in.NextPruneTime, &out.NextPruneTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastPruneStartTime != nil {
+		in, out := &in.LastPruneStartTime, &out.LastPruneStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastPruneDuration != nil {
+		in, out := &in.LastPruneDuration, &out.LastPruneDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticPruneStatus.
+func (in *ResticPruneStatus) DeepCopy() *ResticPruneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticPruneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticRepositoryServer) DeepCopyInto(out *ResticRepositoryServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(ResticRepositoryServerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticRepositoryServer.
+func (in *ResticRepositoryServer) DeepCopy() *ResticRepositoryServer {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticRepositoryServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResticRepositoryServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticRepositoryServerList) DeepCopyInto(out *ResticRepositoryServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResticRepositoryServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticRepositoryServerList.
+func (in *ResticRepositoryServerList) DeepCopy() *ResticRepositoryServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticRepositoryServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResticRepositoryServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticRepositoryServerReference) DeepCopyInto(out *ResticRepositoryServerReference) {
+	*out = *in
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticRepositoryServerReference.
+func (in *ResticRepositoryServerReference) DeepCopy() *ResticRepositoryServerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticRepositoryServerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticRepositoryServerSpec) DeepCopyInto(out *ResticRepositoryServerSpec) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodConfig != nil {
+		in, out := &in.PodConfig, &out.PodConfig
+		*out = new(MoverConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticRepositoryServerSpec.
+func (in *ResticRepositoryServerSpec) DeepCopy() *ResticRepositoryServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticRepositoryServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticRepositoryServerStatus) DeepCopyInto(out *ResticRepositoryServerStatus) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(status.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticRepositoryServerStatus.
+func (in *ResticRepositoryServerStatus) DeepCopy() *ResticRepositoryServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticRepositoryServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResticRetainPolicy) DeepCopyInto(out *ResticRetainPolicy) {
 	*out = *in
@@ -862,3 +1344,166 @@ func (in *ResticRetainPolicy) DeepCopy() *ResticRetainPolicy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticSnapshot) DeepCopyInto(out *ResticSnapshot) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticSnapshot.
+func (in *ResticSnapshot) DeepCopy() *ResticSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticStreamSpec) DeepCopyInto(out *ResticStreamSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filename != nil {
+		in, out := &in.Filename, &out.Filename
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticStreamSpec.
+func (in *ResticStreamSpec) DeepCopy() *ResticStreamSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticStreamSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncProgress) DeepCopyInto(out *SyncProgress) {
+	*out = *in
+	if in.BytesDone != nil {
+		in, out := &in.BytesDone, &out.BytesDone
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BytesTotal != nil {
+		in, out := &in.BytesTotal, &out.BytesTotal
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FilesDone != nil {
+		in, out := &in.FilesDone, &out.FilesDone
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FilesTotal != nil {
+		in, out := &in.FilesTotal, &out.FilesTotal
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PercentDone != nil {
+		in, out := &in.PercentDone, &out.PercentDone
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncProgress.
+func (in *SyncProgress) DeepCopy() *SyncProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncResult) DeepCopyInto(out *SyncResult) {
+	*out = *in
+	if in.BytesTransferred != nil {
+		in, out := &in.BytesTransferred, &out.BytesTransferred
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BytesTotal != nil {
+		in, out := &in.BytesTotal, &out.BytesTotal
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FilesChanged != nil {
+		in, out := &in.FilesChanged, &out.FilesChanged
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FilesTotal != nil {
+		in, out := &in.FilesTotal, &out.FilesTotal
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Details != nil {
+		in, out := &in.Details, &out.Details
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncResult.
+func (in *SyncResult) DeepCopy() *SyncResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncResult)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -48,4 +48,29 @@ type Builder interface {
 	// type, this function should return (nil, nil).
 	FromDestination(client client.Client, logger logr.Logger,
 		destination *scribev1alpha1.ReplicationDestination) (Mover, error)
+
+	// SourceCapabilities describes what the Builder's mover type supports when
+	// replicating from a ReplicationSource.
+	SourceCapabilities() Capabilities
+
+	// DestinationCapabilities describes what the Builder's mover type supports
+	// when replicating to a ReplicationDestination.
+	DestinationCapabilities() Capabilities
+}
+
+// Capabilities describes the features that a data mover supports in one
+// direction of a replication relationship. The controller checks a CR's
+// Requirements against them before any synchronization is attempted.
+type Capabilities struct {
+	// CopyMethods are the supported methods for creating the point-in-time
+	// image of the volume.
+	CopyMethods []scribev1alpha1.CopyMethodType
+
+	// NeedsService indicates that the mover may create a Service to accept
+	// incoming connections.
+	NeedsService bool
+
+	// SecretKeys are the keys that must be present in the user-provided Secret
+	// (see Requirements.Secret).
+	SecretKeys []string
 }
@@ -23,10 +23,20 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 //
 // When an RS or RD CR is reconciled, the Builders in the Catalog are tried in
 // sequence. If one successfully returns a Mover, that mover is used to perform
-// the reconcile.
+// the reconcile. Before synchronizing, the Mover's Requirements are checked
+// against the Builder's Capabilities so that unsupported configurations are
+// reported without creating any objects.
 //
 // Movers implement the actual synchronization of data and return a Result from
 // each invocation. When one of the Mover's functions returns Completed(), the
 // operation (either synchronization or cleanup of a previous synchronization is
 // considered to be completed).
+//
+// While a synchronization is ongoing, Movers may also report its progress via
+// the Result. Mover Jobs publish their progress by annotating their own Job
+// (see ProgressAnnotation), and the Mover reads it back from there.
+//
+// When a mover Job fails, the Mover discards it and returns Failed() with the
+// cause (see JobFailure). The controller records the failure in the CR's
+// status and applies its retryPolicy before synchronizing again.
 package mover
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Failure describes a failed attempt by a data mover to synchronize.
+type Failure struct {
+	// Reason is a brief, CamelCase reason for the failure (e.g., OOMKilled).
+	Reason string
+	// Message is a human-readable description of the failure.
+	Message string
+}
+
+// JobFailure determines why a mover Job has failed. The termination state of
+// the most recently failed pod is preferred, falling back to the Job's own
+// failure condition.
+func JobFailure(ctx context.Context, c client.Client, job *batchv1.Job) *Failure {
+	failure := &Failure{
+		Reason:  "JobFailed",
+		Message: fmt.Sprintf("job %s failed", job.Name),
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			if cond.Reason != "" {
+				failure.Reason = cond.Reason
+			}
+			if cond.Message != "" {
+				failure.Message = cond.Message
+			}
+		}
+	}
+
+	if pod := latestJobPod(ctx, c, job, corev1.PodFailed); pod != nil {
+		if podFailure := podFailure(pod); podFailure != nil {
+			return podFailure
+		}
+	}
+	return failure
+}
+
+// latestJobPod returns the most recently created of a Job's pods that are in
+// the given phase, or nil if there are none.
+func latestJobPod(ctx context.Context, c client.Client, job *batchv1.Job, phase corev1.PodPhase) *corev1.Pod {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return nil
+	}
+	var latest *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != phase {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			latest = pod
+		}
+	}
+	return latest
+}
+
+// podFailure extracts the reason a pod failed from its status
+func podFailure(pod *corev1.Pod) *Failure {
+	for _, cs := range pod.Status.ContainerStatuses {
+		term := cs.State.Terminated
+		if term == nil || term.ExitCode == 0 {
+			continue
+		}
+		reason := term.Reason
+		if reason == "" {
+			reason = "Error"
+		}
+		message := term.Message
+		if message == "" {
+			message = fmt.Sprintf("container %s in pod %s exited with code %d",
+				cs.Name, pod.Name, term.ExitCode)
+		}
+		return &Failure{Reason: reason, Message: message}
+	}
+	if pod.Status.Reason != "" {
+		return &Failure{Reason: pod.Status.Reason, Message: pod.Status.Message}
+	}
+	return nil
+}
@@ -23,8 +23,14 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 )
 
+// MetricsNamespace is the namespace of the Prometheus metrics that are
+// exported by the controllers and the data movers
+const MetricsNamespace = "scribe"
+
 // Mover is a common interface that all data movers implement
 type Mover interface {
 	// The name of this data mover
@@ -38,6 +44,25 @@ type Mover interface {
 	// Cleanup begins or continues the post-synchronization cleanup of temporary
 	// resources. Must be idempotent.
 	Cleanup(ctx context.Context) (Result, error)
+
+	// Requirements describes what the CR has requested of the mover so that it
+	// can be checked against the Builder's Capabilities.
+	Requirements() Requirements
+}
+
+// Requirements are the settings from a CR that depend on the Capabilities of
+// its data mover.
+type Requirements struct {
+	// CopyMethod is the requested method for creating the point-in-time image
+	// of the volume.
+	CopyMethod scribev1alpha1.CopyMethodType
+
+	// ServiceType is the type of Service requested for incoming connections,
+	// if any.
+	ServiceType *v1.ServiceType
+
+	// Secret is the name of the user-provided Secret, if any.
+	Secret *string
 }
 
 // Result indicates the outcome of a synchronization attempt
@@ -56,6 +81,20 @@ type Result struct {
 	// is modified. Setting to 0 indicates an immediate retry. Other values
 	// provide a delay.
 	RetryAfter *time.Duration
+
+	// Progress is the most recent progress report from an ongoing
+	// synchronization. Setting to nil (default) leaves any previously reported
+	// progress unchanged.
+	Progress *scribev1alpha1.SyncProgress
+
+	// Failure is set when an attempt by the mover has failed and has been
+	// discarded so that it can be retried. Each failed attempt should be
+	// reported only once.
+	Failure *Failure
+
+	// SyncResult is the outcome of a completed synchronization, as reported by
+	// the mover. It is only used when Completed is true.
+	SyncResult *scribev1alpha1.SyncResult
 }
 
 // ReconcileResult converts a Result into controllerruntime's reconcile result
@@ -70,10 +109,28 @@ func (mr Result) ReconcileResult() ctrl.Result {
 	return ctrl.Result{}
 }
 
+// WithSyncResult attaches the outcome of a completed synchronization to a
+// Result.
+func (mr Result) WithSyncResult(syncResult *scribev1alpha1.SyncResult) Result {
+	mr.SyncResult = syncResult
+	return mr
+}
+
 // InProgress result indicates that the requested operation is still ongoing,
 // but it does not request an explicit requeueing.
 func InProgress() Result { return Result{} }
 
+// InProgressWith indicates that the requested operation is still ongoing, and
+// it provides the most recent progress report to the controller.
+func InProgressWith(progress *scribev1alpha1.SyncProgress) Result {
+	return Result{Progress: progress}
+}
+
+// Failed indicates that an attempt of the requested operation has failed, and
+// it provides the cause to the controller. The controller decides whether and
+// when the operation is retried.
+func Failed(failure *Failure) Result { return Result{Failure: failure} }
+
 // RetryAfter indicates the operation is ongoing and requests explicit
 // requeueing after the provided duration.
 func RetryAfter(s time.Duration) Result { return Result{RetryAfter: &s} }
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+// ApplyMoverConfig customizes the pod template of a mover Job according to a
+// CR's moverConfig. It should be called each time the Job is reconciled, after
+// the containers have been defined. A nil config removes any previous
+// customization. Since a Job's pod template can't be updated, the Job should be
+// reconciled via CreateOrUpdateJob so that changes reach an existing Job.
+// The resources only apply to the data mover container, which must be the
+// first container of the pod; any others keep their own.
+func ApplyMoverConfig(podSpec *corev1.PodSpec, config *scribev1alpha1.MoverConfig) {
+	if config == nil {
+		config = &scribev1alpha1.MoverConfig{}
+	}
+	config = config.DeepCopy()
+
+	if len(podSpec.Containers) > 0 {
+		podSpec.Containers[0].Resources = corev1.ResourceRequirements{}
+		if config.Resources != nil {
+			podSpec.Containers[0].Resources = *config.Resources
+		}
+	}
+	podSpec.NodeSelector = config.NodeSelector
+	podSpec.Tolerations = config.Tolerations
+	podSpec.Affinity = config.Affinity
+	podSpec.PriorityClassName = ""
+	if config.PriorityClassName != nil {
+		podSpec.PriorityClassName = *config.PriorityClassName
+	}
+}
+
+// CreateOrUpdateJob reconciles a mover Job like ctrlutil.CreateOrUpdate. The
+// pod template of a Job is immutable, so if "mutate" changes the parts of an
+// unfinished Job's template that come from the moverConfig, the Job is deleted
+// instead so that it can be recreated on the next reconcile. The returned bool
+// is true when the Job was deleted.
+func CreateOrUpdateJob(ctx context.Context, c client.Client, logger logr.Logger,
+	job *batchv1.Job, mutate func() error) (bool, error) {
+	changed := false
+	_, err := ctrlutil.CreateOrUpdate(ctx, c, job, func() error {
+		var current *corev1.PodTemplateSpec
+		if !job.CreationTimestamp.IsZero() && job.Status.Succeeded == 0 {
+			current = job.Spec.Template.DeepCopy()
+		}
+		if err := mutate(); err != nil {
+			return err
+		}
+		if current != nil && moverConfigChanged(&current.Spec, &job.Spec.Template.Spec) {
+			// Leave the template alone so the update succeeds
+			changed = true
+			job.Spec.Template = *current
+		}
+		return nil
+	})
+	if err != nil || !changed {
+		return false, err
+	}
+	logger.Info("deleting job -- moverConfig changed")
+	err = c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	return true, client.IgnoreNotFound(err)
+}
+
+// moverConfigChanged compares the fields set by ApplyMoverConfig
+func moverConfigChanged(current *corev1.PodSpec, wanted *corev1.PodSpec) bool {
+	if len(current.Containers) > 0 && len(wanted.Containers) > 0 &&
+		!equality.Semantic.DeepEqual(current.Containers[0].Resources, wanted.Containers[0].Resources) {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(current.NodeSelector, wanted.NodeSelector) ||
+		!equality.Semantic.DeepEqual(current.Tolerations, wanted.Tolerations) ||
+		!equality.Semantic.DeepEqual(current.Affinity, wanted.Affinity) ||
+		current.PriorityClassName != wanted.PriorityClassName
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"encoding/json"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+// ProgressAnnotation is the annotation that a mover Job places on itself to
+// report the progress of the synchronization. Its value is a JSON-encoded
+// SyncProgress.
+const ProgressAnnotation = "scribe.backube/progress"
+
+// ProgressReporterRule is the RBAC rule that permits a mover's ServiceAccount
+// to publish progress reports on the named Job. The termination message is
+// only available once the container has exited, and a pod can't update its own
+// status, so the mover annotates its Job while it runs. The rule is limited to
+// that one Job, and the mover can't use it to change the Job's pod template,
+// which is immutable.
+func ProgressReporterRule(jobName string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{
+		APIGroups:     []string{"batch"},
+		Resources:     []string{"jobs"},
+		ResourceNames: []string{jobName},
+		Verbs:         []string{"get", "patch"},
+	}
+}
+
+// ProgressEnv is the environment that tells the mover container which Job to
+// annotate with its progress reports.
+func ProgressEnv(job *batchv1.Job) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "JOB_NAME", Value: job.Name},
+		{Name: "JOB_NAMESPACE", Value: job.Namespace},
+	}
+}
+
+// ProgressFromJob retrieves the most recent progress report from a mover Job.
+// It returns nil if the Job has not (validly) reported any progress.
+func ProgressFromJob(job *batchv1.Job) *scribev1alpha1.SyncProgress {
+	value, ok := job.GetAnnotations()[ProgressAnnotation]
+	if !ok {
+		return nil
+	}
+	progress := &scribev1alpha1.SyncProgress{}
+	if err := json.Unmarshal([]byte(value), progress); err != nil {
+		return nil
+	}
+
+	// Estimate the completion percentage if the mover didn't provide one,
+	// preferring bytes over files as the measure of work
+	if progress.PercentDone == nil {
+		if percent, ok := percentOf(progress.BytesDone, progress.BytesTotal); ok {
+			progress.PercentDone = &percent
+		} else if percent, ok := percentOf(progress.FilesDone, progress.FilesTotal); ok {
+			progress.PercentDone = &percent
+		}
+	}
+	if progress.PercentDone != nil {
+		percent := *progress.PercentDone
+		if percent < 0 {
+			percent = 0
+		} else if percent > 100 {
+			percent = 100
+		}
+		progress.PercentDone = &percent
+	}
+	return progress
+}
+
+func percentOf(done *int64, total *int64) (int32, bool) {
+	if done == nil || total == nil || *total <= 0 {
+		return 0, false
+	}
+	return int32(*done * 100 / *total), true
+}
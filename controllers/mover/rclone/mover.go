@@ -0,0 +1,340 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rclone
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+	"github.com/backube/scribe/controllers/utils"
+	"github.com/backube/scribe/controllers/volumehandler"
+)
+
+const (
+	mountPath        = "/data"
+	dataVolumeName   = "data"
+	rcloneSecret     = "rclone-secret"
+	rcloneConfigPath = "/rclone-config/"
+)
+
+// Mover is the reconciliation logic for the Rclone-based data mover.
+type Mover struct {
+	client              client.Client
+	logger              logr.Logger
+	owner               metav1.Object
+	vh                  *volumehandler.VolumeHandler
+	rcloneConfigSection *string
+	rcloneDestPath      *string
+	rcloneConfig        *string
+	isSource            bool
+	paused              bool
+	moverConfig         *scribev1alpha1.MoverConfig
+	mainPVCName         *string
+	// failure is the cause of a failed mover Job that has been discarded
+	failure *mover.Failure
+	// syncResult is the result reported by the completed mover Job
+	syncResult *scribev1alpha1.SyncResult
+}
+
+var _ mover.Mover = &Mover{}
+
+// All object types that are temporary/per-iteration should be listed here. The
+// individual objects to be cleaned up must also be marked.
+var cleanupTypes = []client.Object{
+	&corev1.PersistentVolumeClaim{},
+	&snapv1.VolumeSnapshot{},
+	&batchv1.Job{},
+}
+
+func (m *Mover) Name() string { return "rclone" }
+
+func (m *Mover) Requirements() mover.Requirements {
+	return mover.Requirements{
+		CopyMethod: m.vh.GetCopyMethod(),
+		Secret:     m.rcloneConfig,
+	}
+}
+
+func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
+	// Make sure the required fields were provided before allocating anything
+	if err := m.validateSpec(); err != nil {
+		return mover.InProgress(), err
+	}
+
+	var err error
+	// Allocate temporary data PVC
+	var dataPVC *corev1.PersistentVolumeClaim
+	if m.isSource {
+		dataPVC, err = m.ensureSourcePVC(ctx)
+	} else {
+		dataPVC, err = m.ensureDestinationPVC(ctx)
+	}
+	if dataPVC == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Validate rclone config Secret
+	rcloneConfigSecret, err := m.validateRcloneConfig(ctx)
+	if rcloneConfigSecret == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Prepare ServiceAccount
+	sa, err := m.ensureSA(ctx)
+	if sa == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Start mover Job
+	job, err := m.ensureJob(ctx, dataPVC, sa, rcloneConfigSecret)
+	if m.failure != nil {
+		return mover.Failed(m.failure), nil
+	}
+	if job == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// On the destination, preserve the image and return it
+	if !m.isSource {
+		image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
+		if image == nil || err != nil {
+			return mover.InProgress(), err
+		}
+		return mover.CompleteWithImage(image).WithSyncResult(m.syncResult), nil
+	}
+
+	// On the source, just signal completion
+	return mover.Complete().WithSyncResult(m.syncResult), nil
+}
+
+func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
+	if !m.isSource {
+		// Remove the snapshot annotation from the destination PVC so that
+		// the next iteration will take a new snapshot instead of reusing the
+		// one that was just preserved.
+		if err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, m.destinationPVCName()); err != nil {
+			return mover.InProgress(), err
+		}
+	}
+	err := utils.CleanupObjects(ctx, m.client, m.logger, m.owner, cleanupTypes)
+	if err != nil {
+		return mover.InProgress(), err
+	}
+	return mover.Complete(), nil
+}
+
+func (m *Mover) validateSpec() error {
+	if m.rcloneConfig == nil || len(*m.rcloneConfig) == 0 {
+		err := errors.New("Unable to get Rclone config secret name")
+		m.logger.V(1).Info("Unable to get Rclone config secret name")
+		return err
+	}
+	if m.rcloneConfigSection == nil || len(*m.rcloneConfigSection) == 0 {
+		err := errors.New("Unable to get Rclone config section name")
+		m.logger.V(1).Info("Unable to get Rclone config section name")
+		return err
+	}
+	if m.rcloneDestPath == nil || len(*m.rcloneDestPath) == 0 {
+		err := errors.New("Unable to get Rclone destination name")
+		m.logger.V(1).Info("Unable to get Rclone destination name")
+		return err
+	}
+	return nil
+}
+
+func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	srcPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      *m.mainPVCName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := m.client.Get(ctx, utils.NameFor(srcPVC), srcPVC); err != nil {
+		return nil, err
+	}
+	dataName := "scribe-src-" + m.owner.GetName()
+	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
+}
+
+func (m *Mover) destinationPVCName() string {
+	if m.mainPVCName != nil {
+		return *m.mainPVCName
+	}
+	return "scribe-dest-" + m.owner.GetName()
+}
+
+func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	if m.mainPVCName == nil {
+		// Need to allocate the incoming data volume
+		return m.vh.EnsureNewPVC(ctx, m.logger, m.destinationPVCName())
+	}
+
+	// use provided PVC
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      *m.mainPVCName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Get(ctx, utils.NameFor(pvc), pvc)
+	return pvc, err
+}
+
+func (m *Mover) validateRcloneConfig(ctx context.Context) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      *m.rcloneConfig,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("rcloneConfigSecret", utils.NameFor(secret))
+	if err := utils.GetAndValidateSecret(ctx, m.client, logger, secret, "rclone.conf"); err != nil {
+		logger.Error(err, "Rclone config secret does not contain the proper fields")
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (m *Mover) direction() string {
+	if m.isSource {
+		return "src"
+	}
+	return "dst"
+}
+
+func (m *Mover) ensureSA(ctx context.Context) (*corev1.ServiceAccount, error) {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scribe-" + m.direction() + "-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	saDesc := utils.NewSAHandler(ctx, m.client, m.owner, sa)
+	cont, err := saDesc.Reconcile(m.logger)
+	if cont {
+		return sa, err
+	}
+	return nil, err
+}
+
+//nolint:funlen
+func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
+	sa *corev1.ServiceAccount, rcloneConfigSecret *corev1.Secret) (*batchv1.Job, error) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scribe-rclone-" + m.direction() + "-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("job", utils.NameFor(job))
+	deleted, err := mover.CreateOrUpdateJob(ctx, m.client, logger, job, func() error {
+		if err := ctrl.SetControllerReference(m.owner, job, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		utils.MarkForCleanup(m.owner, job)
+		job.Spec.Template.ObjectMeta.Name = job.Name
+		backoffLimit := int32(2)
+		job.Spec.BackoffLimit = &backoffLimit
+		parallelism := int32(1)
+		if m.paused {
+			parallelism = int32(0)
+		}
+		job.Spec.Parallelism = &parallelism
+
+		direction := "source"
+		if !m.isSource {
+			direction = "destination"
+		}
+		runAsUser := int64(0)
+		job.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name: "rclone",
+			Env: []corev1.EnvVar{
+				{Name: "RCLONE_CONFIG", Value: rcloneConfigPath + "rclone.conf"},
+				{Name: "RCLONE_DEST_PATH", Value: *m.rcloneDestPath},
+				{Name: "DIRECTION", Value: direction},
+				{Name: "MOUNT_PATH", Value: mountPath},
+				{Name: "RCLONE_CONFIG_SECTION", Value: *m.rcloneConfigSection},
+			},
+			Command: []string{"/bin/bash", "-c", "./active.sh"},
+			Image:   rcloneContainerImage,
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser: &runAsUser,
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: dataVolumeName, MountPath: mountPath},
+				{Name: rcloneSecret, MountPath: rcloneConfigPath},
+			},
+		}}
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		job.Spec.Template.Spec.ServiceAccountName = sa.Name
+		secretMode := int32(0600)
+		job.Spec.Template.Spec.Volumes = []corev1.Volume{
+			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dataPVC.Name,
+				}},
+			},
+			{Name: rcloneSecret, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  rcloneConfigSecret.Name,
+					DefaultMode: &secretMode,
+				}},
+			},
+		}
+		mover.ApplyMoverConfig(&job.Spec.Template.Spec, m.moverConfig)
+		return nil
+	})
+	if deleted {
+		return nil, err
+	}
+	// If Job had failed, delete it so it can be recreated
+	if job.Status.Failed >= *job.Spec.BackoffLimit {
+		failure := mover.JobFailure(ctx, m.client, job)
+		logger.Info("deleting job -- backoff limit reached", "reason", failure.Reason)
+		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err == nil {
+			m.failure = failure
+		}
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(err, "reconcile failed")
+		return nil, err
+	}
+
+	// Stop here if the job hasn't completed yet
+	if job.Status.Succeeded == 0 {
+		return nil, nil
+	}
+
+	logger.Info("job completed")
+	m.syncResult = mover.SyncResultFromJob(ctx, m.client, job)
+	// We only continue reconciling if the rclone job has completed
+	return job, nil
+}
@@ -0,0 +1,381 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rclone
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+)
+
+var _ = Describe("Rclone properly registers", func() {
+	When("Rclone's registration function is called", func() {
+		BeforeEach(func() {
+			Register()
+		})
+		It("is added to the mover catalog", func() {
+			found := false
+			for _, v := range mover.Catalog {
+				if _, ok := v.(*Builder); ok {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Rclone ignores other movers", func() {
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	When("An RS isn't for rclone", func() {
+		It("is ignored", func() {
+			rs := &scribev1alpha1.ReplicationSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cr",
+					Namespace: "blah",
+				},
+				Spec: scribev1alpha1.ReplicationSourceSpec{
+					Rclone: nil,
+				},
+			}
+			builder := Builder{}
+			m, e := builder.FromSource(k8sClient, logger, rs)
+			Expect(m).To(BeNil())
+			Expect(e).NotTo(HaveOccurred())
+		})
+	})
+	When("An RD isn't for rclone", func() {
+		It("is ignored", func() {
+			rd := &scribev1alpha1.ReplicationDestination{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "x",
+					Namespace: "y",
+				},
+				Spec: scribev1alpha1.ReplicationDestinationSpec{
+					Rclone: nil,
+				},
+			}
+			builder := Builder{}
+			m, e := builder.FromDestination(k8sClient, logger, rd)
+			Expect(m).To(BeNil())
+			Expect(e).NotTo(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Rclone as a source", func() {
+	var ctx = context.TODO()
+	var ns *v1.Namespace
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	var rs *scribev1alpha1.ReplicationSource
+	var sPVC *v1.PersistentVolumeClaim
+	var mover *Mover
+	BeforeEach(func() {
+		// Create namespace for test
+		ns = &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "rclone-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		Expect(ns.Name).NotTo(BeEmpty())
+
+		sPVC = &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "s",
+				Namespace: ns.Name,
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{
+					v1.ReadWriteOnce,
+				},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						"storage": resource.MustParse("7Gi"),
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, sPVC)).To(Succeed())
+
+		configSecret := "rclone-secret"
+		configSection := "remote"
+		destPath := "bucket/path"
+		// Scaffold ReplicationSource
+		rs = &scribev1alpha1.ReplicationSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rs",
+				Namespace: ns.Name,
+			},
+			Spec: scribev1alpha1.ReplicationSourceSpec{
+				SourcePVC: sPVC.Name,
+				Trigger:   &scribev1alpha1.ReplicationSourceTriggerSpec{},
+				Rclone: &scribev1alpha1.ReplicationSourceRcloneSpec{
+					RcloneConfig:        &configSecret,
+					RcloneConfigSection: &configSection,
+					RcloneDestPath:      &destPath,
+				},
+				Paused: false,
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, rs)).To(Succeed())
+		// Controller sets status to non-nil
+		rs.Status = &scribev1alpha1.ReplicationSourceStatus{}
+		// Instantiate an rclone mover for the tests
+		b := Builder{}
+		m, err := b.FromSource(k8sClient, logger, rs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m).NotTo(BeNil())
+		mover, _ = m.(*Mover)
+		Expect(mover).NotTo(BeNil())
+	})
+	AfterEach(func() {
+		// All resources are namespaced, so this should clean it all up
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
+	})
+
+	When("a required field is empty", func() {
+		BeforeEach(func() {
+			empty := ""
+			rs.Spec.Rclone.RcloneDestPath = &empty
+		})
+		It("fails validation without creating anything", func() {
+			result, err := mover.Synchronize(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(result.Completed).To(BeFalse())
+			pvc := &v1.PersistentVolumeClaim{}
+			nsn := types.NamespacedName{Name: "scribe-src-" + rs.Name, Namespace: ns.Name}
+			Consistently(func() error {
+				return k8sClient.Get(ctx, nsn, pvc)
+			}, "2s", "500ms").ShouldNot(Succeed())
+		})
+	})
+
+	When("the rclone config Secret is missing its key", func() {
+		BeforeEach(func() {
+			secret := &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      *rs.Spec.Rclone.RcloneConfig,
+					Namespace: ns.Name,
+				},
+				StringData: map[string]string{
+					"wrong.conf": "foo",
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		})
+		It("is an error", func() {
+			var err error
+			Eventually(func() error {
+				_, err = mover.validateRcloneConfig(ctx)
+				return err
+			}, "5s", "1s").Should(MatchError(ContainSubstring("rclone.conf")))
+		})
+	})
+
+	Context("mover Job is handled properly", func() {
+		var sa *v1.ServiceAccount
+		var secret *v1.Secret
+		var job *batchv1.Job
+		var nsn types.NamespacedName
+		BeforeEach(func() {
+			sa = &v1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thesa",
+					Namespace: ns.Name,
+				},
+			}
+			secret = &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thesecret",
+					Namespace: ns.Name,
+				},
+			}
+		})
+		JustBeforeEach(func() {
+			rcloneContainerImage = "thecontainerimage"
+			// hardcoded since we don't get access unless the job is
+			// completed
+			nsn = types.NamespacedName{Name: "scribe-rclone-src-" + rs.Name, Namespace: ns.Name}
+			j, e := mover.ensureJob(ctx, sPVC, sa, secret)
+			Expect(e).NotTo(HaveOccurred())
+			Expect(j).To(BeNil()) // hasn't completed
+			job = &batchv1.Job{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, nsn, job)
+			}, "5s", "1s").Should(Succeed())
+		})
+		It("uses the specified container image, SA, and Secret", func() {
+			Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+			c := job.Spec.Template.Spec.Containers[0]
+			Expect(c.Image).To(Equal(rcloneContainerImage))
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "DIRECTION", Value: "source"}))
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "RCLONE_DEST_PATH", Value: "bucket/path"}))
+			Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal(sa.Name))
+			found := false
+			for _, vol := range job.Spec.Template.Spec.Volumes {
+				if vol.Secret != nil && vol.Secret.SecretName == secret.Name {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+		It("is marked for cleanup", func() {
+			Expect(job.Labels).To(HaveKeyWithValue("scribe.backube/cleanup", string(rs.UID)))
+		})
+		When("the mover is paused", func() {
+			BeforeEach(func() {
+				rs.Spec.Paused = true
+			})
+			It("sets the Job's parallelism to 0", func() {
+				Expect(*job.Spec.Parallelism).To(Equal(int32(0)))
+			})
+		})
+		When("the job has failed", func() {
+			It("is deleted so it can be restarted", func() {
+				job.Status.Failed = *job.Spec.BackoffLimit
+				Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+				Eventually(func() bool {
+					_, _ = mover.ensureJob(ctx, sPVC, sa, secret)
+					err := k8sClient.Get(ctx, nsn, job)
+					return err != nil || !job.DeletionTimestamp.IsZero()
+				}, "10s", "1s").Should(BeTrue())
+			})
+		})
+	})
+})
+
+var _ = Describe("Rclone as a destination", func() {
+	var ctx = context.TODO()
+	var ns *v1.Namespace
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	var rd *scribev1alpha1.ReplicationDestination
+	var mover *Mover
+	BeforeEach(func() {
+		// Create namespace for test
+		ns = &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "rclone-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		Expect(ns.Name).NotTo(BeEmpty())
+
+		configSecret := "rclone-secret"
+		configSection := "remote"
+		destPath := "bucket/path"
+		capacity := resource.MustParse("2Gi")
+		rd = &scribev1alpha1.ReplicationDestination{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rd",
+				Namespace: ns.Name,
+			},
+			Spec: scribev1alpha1.ReplicationDestinationSpec{
+				Trigger: &scribev1alpha1.ReplicationDestinationTriggerSpec{},
+				Rclone: &scribev1alpha1.ReplicationDestinationRcloneSpec{
+					ReplicationDestinationVolumeOptions: scribev1alpha1.ReplicationDestinationVolumeOptions{
+						CopyMethod:  scribev1alpha1.CopyMethodSnapshot,
+						Capacity:    &capacity,
+						AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					},
+					RcloneConfig:        &configSecret,
+					RcloneConfigSection: &configSection,
+					RcloneDestPath:      &destPath,
+				},
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, rd)).To(Succeed())
+		// Controller sets status to non-nil
+		rd.Status = &scribev1alpha1.ReplicationDestinationStatus{}
+		// Instantiate an rclone mover for the tests
+		b := Builder{}
+		m, err := b.FromDestination(k8sClient, logger, rd)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m).NotTo(BeNil())
+		mover, _ = m.(*Mover)
+		Expect(mover).NotTo(BeNil())
+	})
+	AfterEach(func() {
+		// All resources are namespaced, so this should clean it all up
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
+	})
+
+	When("no destination volume is supplied", func() {
+		It("creates a PVC", func() {
+			pvc, err := mover.ensureDestinationPVC(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pvc).NotTo(BeNil())
+			Expect(pvc.Name).To(Equal("scribe-dest-" + rd.Name))
+		})
+	})
+
+	It("names the Job and ServiceAccount for the destination", func() {
+		sa, err := mover.ensureSA(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sa).NotTo(BeNil())
+		Expect(sa.Name).To(Equal("scribe-dst-" + rd.Name))
+		pvc, err := mover.ensureDestinationPVC(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "thesecret", Namespace: ns.Name}}
+		_, err = mover.ensureJob(ctx, pvc, sa, secret)
+		Expect(err).NotTo(HaveOccurred())
+		job := &batchv1.Job{}
+		nsn := types.NamespacedName{Name: "scribe-rclone-dst-" + rd.Name, Namespace: ns.Name}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, nsn, job)
+		}, "5s", "1s").Should(Succeed())
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(
+			ContainElement(v1.EnvVar{Name: "DIRECTION", Value: "destination"}))
+	})
+
+	When("the Cleanup runs", func() {
+		It("removes the snapshot annotation from the destination PVC", func() {
+			pvc, err := mover.ensureDestinationPVC(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			pvc.Annotations = map[string]string{"scribe.backube/snapname": "snap"}
+			Expect(k8sClient.Update(ctx, pvc)).To(Succeed())
+			nsn := types.NamespacedName{Name: pvc.Name, Namespace: ns.Name}
+			Eventually(func() map[string]string {
+				_ = k8sClient.Get(ctx, nsn, pvc)
+				return pvc.Annotations
+			}, "5s", "1s").Should(HaveKey("scribe.backube/snapname"))
+
+			result, err := mover.Cleanup(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Eventually(func() map[string]string {
+				_ = k8sClient.Get(ctx, nsn, pvc)
+				return pvc.Annotations
+			}, "5s", "1s").ShouldNot(HaveKey("scribe.backube/snapname"))
+		})
+	})
+})
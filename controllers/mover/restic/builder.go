@@ -57,6 +57,13 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		source.Status.Restic = &scribev1alpha1.ReplicationSourceResticStatus{}
 	}
 
+	// Snapshots are identified by the source's namespace/name unless
+	// overridden
+	hostname := source.Namespace + "/" + source.Name
+	if source.Spec.Restic.Hostname != nil {
+		hostname = *source.Spec.Restic.Hostname
+	}
+
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
 		volumehandler.WithOwner(source),
@@ -74,13 +81,31 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 		cacheAccessModes:      source.Spec.Restic.CacheAccessModes,
 		cacheCapacity:         source.Spec.Restic.CacheCapacity,
 		cacheStorageClassName: source.Spec.Restic.CacheStorageClassName,
+		cacheMode:             source.Spec.Restic.CacheMode,
 		repositoryName:        source.Spec.Restic.Repository,
+		repositoryPath:        source.Spec.Restic.RepositoryPath,
+		repositoryPVC:         source.Spec.Restic.RepositoryPVC,
+		repositoryServer:      source.Spec.Restic.RepositoryServer,
+		customCA:              source.Spec.Restic.CustomCA,
+		staleLockAge:          source.Spec.Restic.StaleLockAge,
 		isSource:              true,
 		paused:                source.Spec.Paused,
+		moverConfig:           source.Spec.MoverConfig,
 		mainPVCName:           &source.Spec.SourcePVC,
+		hostname:              hostname,
+		tags:                  source.Spec.Restic.Tags,
+		stream:                source.Spec.Restic.Stream,
 		pruneInterval:         source.Spec.Restic.PruneIntervalDays,
+		pruneSchedule:         source.Spec.Restic.PruneSchedule,
+		checkInterval:         source.Spec.Restic.CheckIntervalDays,
+		readDataSubset:        source.Spec.Restic.ReadDataSubset,
 		retainPolicy:          source.Spec.Restic.Retain,
+		include:               source.Spec.Restic.Include,
+		exclude:               source.Spec.Restic.Exclude,
+		excludeCaches:         source.Spec.Restic.ExcludeCaches,
+		excludeIfPresent:      source.Spec.Restic.ExcludeIfPresent,
 		sourceStatus:          source.Status.Restic,
+		conditions:            &source.Status.Conditions,
 	}, nil
 }
 
@@ -91,6 +116,17 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		return nil, nil
 	}
 
+	// Make sure there's a place to write status info
+	if destination.Status.Restic == nil {
+		destination.Status.Restic = &scribev1alpha1.ReplicationDestinationResticStatus{}
+	}
+
+	// By default, snapshots from any host may be restored
+	hostname := ""
+	if destination.Spec.Restic.Hostname != nil {
+		hostname = *destination.Spec.Restic.Hostname
+	}
+
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
 		volumehandler.WithOwner(destination),
@@ -108,9 +144,49 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		cacheAccessModes:      destination.Spec.Restic.CacheAccessModes,
 		cacheCapacity:         destination.Spec.Restic.CacheCapacity,
 		cacheStorageClassName: destination.Spec.Restic.CacheStorageClassName,
+		cacheMode:             destination.Spec.Restic.CacheMode,
 		repositoryName:        destination.Spec.Restic.Repository,
+		repositoryPath:        destination.Spec.Restic.RepositoryPath,
+		repositoryPVC:         destination.Spec.Restic.RepositoryPVC,
+		repositoryServer:      destination.Spec.Restic.RepositoryServer,
+		customCA:              destination.Spec.Restic.CustomCA,
+		staleLockAge:          destination.Spec.Restic.StaleLockAge,
+		conditions:            &destination.Status.Conditions,
 		isSource:              false,
 		paused:                destination.Spec.Paused,
+		moverConfig:           destination.Spec.MoverConfig,
 		mainPVCName:           destination.Spec.Restic.DestinationPVC,
+		hostname:              hostname,
+		tags:                  destination.Spec.Restic.Tags,
+		stream:                destination.Spec.Restic.Stream,
+		restoreAsOf:           destination.Spec.Restic.RestoreAsOf,
+		snapshotID:            destination.Spec.Restic.SnapshotID,
+		restoreMode:           destination.Spec.Restic.RestoreMode,
+		destStatus:            destination.Status.Restic,
 	}, nil
 }
+
+func (rb *Builder) SourceCapabilities() mover.Capabilities {
+	return mover.Capabilities{
+		CopyMethods: []scribev1alpha1.CopyMethodType{
+			scribev1alpha1.CopyMethodNone,
+			scribev1alpha1.CopyMethodClone,
+			scribev1alpha1.CopyMethodSnapshot,
+		},
+		// RESTIC_REPOSITORY isn't needed with a repositoryPVC, so it's
+		// checked by the mover
+		SecretKeys: []string{"RESTIC_PASSWORD"},
+	}
+}
+
+func (rb *Builder) DestinationCapabilities() mover.Capabilities {
+	return mover.Capabilities{
+		CopyMethods: []scribev1alpha1.CopyMethodType{
+			scribev1alpha1.CopyMethodNone,
+			scribev1alpha1.CopyMethodSnapshot,
+		},
+		// RESTIC_REPOSITORY isn't needed with a repositoryPVC, so it's
+		// checked by the mover
+		SecretKeys: []string{"RESTIC_PASSWORD"},
+	}
+}
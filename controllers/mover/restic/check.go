@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/operator-framework/operator-lib/status"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+const (
+	// checkAnnotation is the annotation that a Job places on itself to report
+	// the outcome of a repository check. Its value is a JSON-encoded
+	// checkReport.
+	checkAnnotation = "scribe.backube/restic-check"
+	// Values of .status.restic.lastCheckResult
+	checkResultPassed = "Passed"
+	checkResultFailed = "Failed"
+)
+
+// checkReport is the outcome of a repository check reported by a Job
+type checkReport struct {
+	// Healthy is true if the check found no errors
+	Healthy bool `json:"healthy"`
+	// Message summarizes the errors that were found
+	Message string `json:"message,omitempty"`
+}
+
+// shouldCheck determines whether the repository is due for an integrity check
+func (m *Mover) shouldCheck(current time.Time) bool {
+	if m.checkInterval == nil {
+		return false
+	}
+	delta := time.Hour * 24 * time.Duration(*m.checkInterval)
+	// If we've never checked, the 1st one should be "delta" after creation.
+	lastChecked := m.owner.GetCreationTimestamp().Time
+	if !m.sourceStatus.LastChecked.IsZero() {
+		lastChecked = m.sourceStatus.LastChecked.Time
+	}
+	return current.After(lastChecked.Add(delta))
+}
+
+// jobHasAction determines whether the Job was asked to perform the action
+func jobHasAction(job *batchv1.Job, action string) bool {
+	for _, c := range job.Spec.Template.Spec.Containers {
+		for _, arg := range c.Args {
+			if arg == action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkFromJob retrieves the outcome of the repository check from a completed
+// Job. It returns nil if there is no (valid) report.
+func checkFromJob(job *batchv1.Job) *checkReport {
+	value, ok := job.GetAnnotations()[checkAnnotation]
+	if !ok {
+		return nil
+	}
+	report := &checkReport{}
+	if err := json.Unmarshal([]byte(value), report); err != nil {
+		return nil
+	}
+	return report
+}
+
+// updateCheck records the outcome of a repository check in the status
+func (m *Mover) updateCheck(report *checkReport) {
+	now := metav1.Now()
+	m.sourceStatus.LastChecked = &now
+	if report == nil {
+		m.logger.Info("repository check did not report its outcome")
+		return
+	}
+
+	cond := status.Condition{
+		Type:    scribev1alpha1.ConditionRepositoryHealthy,
+		Status:  corev1.ConditionTrue,
+		Reason:  scribev1alpha1.RepositoryHealthyReasonCheckPassed,
+		Message: "Repository check found no errors",
+	}
+	m.sourceStatus.LastCheckResult = checkResultPassed
+	if !report.Healthy {
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = scribev1alpha1.RepositoryHealthyReasonCheckFailed
+		cond.Message = "Repository check found errors: " + report.Message
+		m.sourceStatus.LastCheckResult = checkResultFailed
+	}
+	m.conditions.SetCondition(cond)
+	m.logger.Info("repository check completed", "result", m.sourceStatus.LastCheckResult)
+}
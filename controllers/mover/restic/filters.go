@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/backube/scribe/controllers/mover"
+	"github.com/backube/scribe/controllers/utils"
+)
+
+const (
+	filtersVolumeName = "filters"
+	filtersMountPath  = "/filters"
+)
+
+// filtersName is the name of the ConfigMap holding the backup filters
+func (m *Mover) filtersName() string {
+	return "scribe-restic-filters-" + m.owner.GetName()
+}
+
+// validateFilters ensures that the include/exclude patterns can be written to
+// the filter files and that they refer to paths within the volume
+func (m *Mover) validateFilters() error {
+	for _, list := range [][]string{m.include, m.exclude, m.excludeIfPresent} {
+		for _, pattern := range list {
+			if pattern == "" || strings.ContainsAny(pattern, "\r\n") {
+				return fmt.Errorf("%w: invalid filter pattern %q", mover.ErrInvalidSpec, pattern)
+			}
+		}
+	}
+	for _, pattern := range m.include {
+		if path.Clean("/"+pattern) == "/" || hasParentRef(pattern) {
+			return fmt.Errorf("%w: include pattern %q must refer to a path within the volume",
+				mover.ErrInvalidSpec, pattern)
+		}
+	}
+	for _, name := range m.excludeIfPresent {
+		if strings.Contains(name, "/") {
+			return fmt.Errorf("%w: excludeIfPresent entry %q must be a file name",
+				mover.ErrInvalidSpec, name)
+		}
+	}
+	return nil
+}
+
+// ensureFilters writes the backup filters into a ConfigMap that is mounted
+// into the mover Job. Each key holds one pattern per line.
+func (m *Mover) ensureFilters(ctx context.Context) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.filtersName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("filters", utils.NameFor(cm))
+	_, err := ctrlutil.CreateOrUpdate(ctx, m.client, cm, func() error {
+		if err := ctrl.SetControllerReference(m.owner, cm, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		excludeCaches := ""
+		if m.excludeCaches {
+			excludeCaches = "true"
+		}
+		cm.Data = map[string]string{
+			"include":            filterLines(m.include),
+			"exclude":            filterLines(m.exclude),
+			"exclude-caches":     excludeCaches,
+			"exclude-if-present": filterLines(m.excludeIfPresent),
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "reconcile failed")
+		return nil, err
+	}
+	return cm, nil
+}
+
+// hasParentRef determines whether a path contains a ".." element
+func hasParentRef(p string) bool {
+	for _, elem := range strings.Split(p, "/") {
+		if elem == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func filterLines(patterns []string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	return strings.Join(patterns, "\n") + "\n"
+}
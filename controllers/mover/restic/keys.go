@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"encoding/json"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+const (
+	// newPasswordKey is the key of the repository Secret that holds the
+	// password that should replace RESTIC_PASSWORD
+	newPasswordKey = "RESTIC_NEW_PASSWORD"
+	// keyAnnotation is the annotation that a Job places on itself to report
+	// the repository key that was created by a key rotation. Its value is a
+	// JSON-encoded keyReport.
+	keyAnnotation = "scribe.backube/restic-key"
+	// retireKeyAnnotation is placed on the ReplicationSource by the user to
+	// remove the repository key for the old password. Its value must be the
+	// oldKeyID of the completed rotation.
+	retireKeyAnnotation = "scribe.backube/restic-retire-key"
+	// Values of .status.restic.keyRotation.phase
+	keyRotationInProgress = "InProgress"
+	keyRotationCompleted  = "Completed"
+	keyRotationRetired    = "Retired"
+)
+
+// keyReport describes the repository keys for the new and old passwords
+type keyReport struct {
+	KeyID    string `json:"keyID"`
+	OldKeyID string `json:"oldKeyID"`
+}
+
+// shouldRotateKey determines whether the repository's password should be
+// replaced by the new password from the repository Secret. The old key can't
+// be removed from an append-only repository. Once the new password has been
+// installed, it isn't rotated again while the Secret remains unchanged.
+func (m *Mover) shouldRotateKey(repo *corev1.Secret) bool {
+	if _, ok := repo.Data[newPasswordKey]; !ok || !m.isSource || m.appendOnly {
+		return false
+	}
+	rotation := m.sourceStatus.KeyRotation
+	return rotation == nil || rotation.Phase == keyRotationInProgress ||
+		rotation.SecretResourceVersion != repo.ResourceVersion
+}
+
+// shouldRetireKey determines whether the key for the password that was
+// replaced by a key rotation should be removed. Other ReplicationSources and
+// ReplicationDestinations sharing the repository may still use the old
+// password, so the key is kept until the user annotates the source with its ID.
+func (m *Mover) shouldRetireKey() bool {
+	rotation := m.sourceStatus.KeyRotation
+	if !m.isSource || m.appendOnly || rotation == nil ||
+		rotation.Phase != keyRotationCompleted || rotation.OldKeyID == "" {
+		return false
+	}
+	return m.owner.GetAnnotations()[retireKeyAnnotation] == rotation.OldKeyID
+}
+
+// updateKeyRotation records the state of the key rotation or retirement
+// performed by the Job in the status
+func (m *Mover) updateKeyRotation(job *batchv1.Job, repo *corev1.Secret) {
+	if jobHasAction(job, "retire-key") && job.Status.Succeeded > 0 &&
+		m.sourceStatus.KeyRotation != nil && m.sourceStatus.KeyRotation.OldKeyID != "" {
+		m.logger.Info("repository key retired", "keyID", m.sourceStatus.KeyRotation.OldKeyID)
+		m.sourceStatus.KeyRotation.Phase = keyRotationRetired
+		m.sourceStatus.KeyRotation.OldKeyID = ""
+		return
+	}
+	if !jobHasAction(job, "rotate-key") {
+		return
+	}
+	if job.Status.Succeeded == 0 {
+		if m.sourceStatus.KeyRotation == nil ||
+			m.sourceStatus.KeyRotation.Phase != keyRotationInProgress {
+			m.sourceStatus.KeyRotation = &scribev1alpha1.ResticKeyRotationStatus{
+				Phase: keyRotationInProgress,
+			}
+		}
+		return
+	}
+
+	now := metav1.Now()
+	m.sourceStatus.KeyRotation = &scribev1alpha1.ResticKeyRotationStatus{
+		Phase:                 keyRotationCompleted,
+		CompletionTime:        &now,
+		SecretResourceVersion: repo.ResourceVersion,
+	}
+	report := keyReport{}
+	if err := json.Unmarshal([]byte(job.GetAnnotations()[keyAnnotation]), &report); err == nil {
+		m.sourceStatus.KeyRotation.KeyID = report.KeyID
+		m.sourceStatus.KeyRotation.OldKeyID = report.OldKeyID
+	}
+	m.logger.Info("repository key rotation completed", "keyID", m.sourceStatus.KeyRotation.KeyID,
+		"oldKeyID", m.sourceStatus.KeyRotation.OldKeyID)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/operator-framework/operator-lib/status"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+)
+
+// lockAnnotation is the annotation that a Job places on itself to report the
+// locks it found in the repository when it started. Its value is a
+// JSON-encoded lockReport.
+const lockAnnotation = "scribe.backube/restic-locks"
+
+// lockReport describes the repository's locks
+type lockReport struct {
+	// Count is the number of locks that were present
+	Count int `json:"count"`
+	// Oldest is the creation time of the oldest lock
+	Oldest string `json:"oldest,omitempty"`
+	// Removed is true if the locks were removed as being stale
+	Removed bool `json:"removed"`
+}
+
+// minStaleLockAge is the smallest staleLockAge that is accepted. Running movers
+// refresh their locks every 5 minutes, but a mover that is starved of CPU or
+// is waiting on a slow repository may fall well behind, so the margin is wide.
+const minStaleLockAge = 30 * time.Minute
+
+// validateStaleLockAge ensures that locks held by running movers can't be
+// mistaken for stale ones
+func (m *Mover) validateStaleLockAge() error {
+	if m.staleLockAge != nil && m.staleLockAge.Duration < minStaleLockAge {
+		return fmt.Errorf("%w: staleLockAge must be at least %v",
+			mover.ErrInvalidSpec, minStaleLockAge)
+	}
+	return nil
+}
+
+// staleLockAgeOption is the age, in seconds, after which locks are removed
+func (m *Mover) staleLockAgeOption() string {
+	if m.staleLockAge == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(m.staleLockAge.Seconds()), 10)
+}
+
+// updateLocks sets the RepositoryLocked condition based on the locks reported
+// by the Job. The repository is only considered locked if the Job has failed
+// while there were locks that it did not remove.
+func (m *Mover) updateLocks(job *batchv1.Job) {
+	value, ok := job.GetAnnotations()[lockAnnotation]
+	if !ok {
+		return
+	}
+	report := lockReport{}
+	if err := json.Unmarshal([]byte(value), &report); err != nil {
+		return
+	}
+
+	cond := status.Condition{
+		Type:    scribev1alpha1.ConditionRepositoryLocked,
+		Status:  corev1.ConditionFalse,
+		Reason:  scribev1alpha1.RepositoryLockedReasonUnlocked,
+		Message: "No locks are blocking the repository",
+	}
+	switch {
+	case report.Removed:
+		cond.Reason = scribev1alpha1.RepositoryLockedReasonStaleLockRemoved
+		cond.Message = fmt.Sprintf("Removed %d stale lock(s), the oldest created at %s",
+			report.Count, report.Oldest)
+	case report.Count > 0 && job.Status.Failed > 0 && job.Status.Succeeded == 0:
+		cond.Status = corev1.ConditionTrue
+		cond.Reason = scribev1alpha1.RepositoryLockedReasonLockHeld
+		cond.Message = fmt.Sprintf("The mover failed while the repository had %d lock(s), the oldest created at %s. ",
+			report.Count, report.Oldest)
+		if m.staleLockAge == nil {
+			cond.Message += "If they are stale, remove them via restic unlock or set staleLockAge."
+		} else {
+			cond.Message += fmt.Sprintf("They will be removed once all are older than %s.",
+				m.staleLockAge.Duration)
+		}
+	}
+	m.conditions.SetCondition(cond)
+}
@@ -20,18 +20,22 @@ package restic
 import (
 	"context"
 	"fmt"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	"github.com/operator-framework/operator-lib/status"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 	"github.com/backube/scribe/controllers/mover"
@@ -44,6 +48,9 @@ const (
 	mountPath            = "/data"
 	dataVolumeName       = "data"
 	resticCache          = "cache"
+	// allowMirrorAnnotation is placed on a destinationPVC to permit a Mirror
+	// restore to delete the files on it that aren't in the snapshot
+	allowMirrorAnnotation = "scribe.backube/restic-allow-mirror"
 )
 
 // Mover is the reconciliation logic for the Restic-based data mover.
@@ -55,14 +62,51 @@ type Mover struct {
 	cacheAccessModes      []v1.PersistentVolumeAccessMode
 	cacheCapacity         *resource.Quantity
 	cacheStorageClassName *string
+	cacheMode             *scribev1alpha1.ResticCacheMode
 	repositoryName        string
+	repositoryPath        *string
+	repositoryPVC         *string
+	repositoryServer      *scribev1alpha1.ResticRepositoryServerReference
+	customCA              *scribev1alpha1.CustomCASpec
+	staleLockAge          *metav1.Duration
 	isSource              bool
 	paused                bool
+	moverConfig           *scribev1alpha1.MoverConfig
 	mainPVCName           *string
+	conditions            *status.Conditions
+	// hostname and tags identify the source's snapshots. On the destination,
+	// they limit the snapshots that may be restored.
+	hostname string
+	tags     []string
+	// stream replaces the contents of the volume with the output of a command
+	stream *scribev1alpha1.ResticStreamSpec
+	// progress is the most recent progress report from the mover Job
+	progress *scribev1alpha1.SyncProgress
+	// failure is the cause of a failed mover Job that has been discarded
+	failure *mover.Failure
+	// syncResult is the result reported by the completed mover Job
+	syncResult *scribev1alpha1.SyncResult
+	// appendOnly is set when the repository server doesn't permit data to be
+	// removed from the repository
+	appendOnly bool
+	// repositoryNode is where the ReadWriteOnce repository PVC is attached
+	repositoryNode string
 	// Source-only fields
-	pruneInterval *int32
-	retainPolicy  *scribev1alpha1.ResticRetainPolicy
-	sourceStatus  *scribev1alpha1.ReplicationSourceResticStatus
+	pruneInterval    *int32
+	pruneSchedule    *string
+	checkInterval    *int32
+	readDataSubset   *string
+	retainPolicy     *scribev1alpha1.ResticRetainPolicy
+	include          []string
+	exclude          []string
+	excludeCaches    bool
+	excludeIfPresent []string
+	sourceStatus     *scribev1alpha1.ReplicationSourceResticStatus
+	// Destination-only fields
+	restoreAsOf *metav1.Time
+	snapshotID  *string
+	restoreMode *scribev1alpha1.ResticRestoreMode
+	destStatus  *scribev1alpha1.ReplicationDestinationResticStatus
 }
 
 var _ mover.Mover = &Mover{}
@@ -77,23 +121,57 @@ var cleanupTypes = []client.Object{
 
 func (m *Mover) Name() string { return "restic" }
 
+func (m *Mover) Requirements() mover.Requirements {
+	req := mover.Requirements{
+		CopyMethod: m.vh.GetCopyMethod(),
+	}
+	// The credentials for a repository server are generated, so they are
+	// checked by the mover once they exist
+	if m.repositoryServer == nil {
+		req.Secret = &m.repositoryName
+	}
+	return req
+}
+
 func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
+	result, err := m.synchronize(ctx)
+	return m.withPrune(ctx, result, err)
+}
+
+func (m *Mover) synchronize(ctx context.Context) (mover.Result, error) {
+	// Make sure the spec is consistent before allocating anything
+	if err := m.validateSpec(); err != nil {
+		return mover.InProgress(), err
+	}
+
 	var err error
 	// Allocate temporary data PVC
 	var dataPVC *v1.PersistentVolumeClaim
 	if m.isSource {
-		dataPVC, err = m.ensureSourcePVC(ctx)
+		// A stream doesn't have to be read from a volume
+		if m.needsSourcePVC() {
+			dataPVC, err = m.ensureSourcePVC(ctx)
+			if dataPVC == nil || err != nil {
+				return mover.InProgress(), err
+			}
+		}
 	} else {
 		dataPVC, err = m.ensureDestinationPVC(ctx)
-	}
-	if dataPVC == nil || err != nil {
-		return mover.InProgress(), err
+		if dataPVC == nil || err != nil {
+			return mover.InProgress(), err
+		}
+		if err := m.validateRestoreTarget(dataPVC); err != nil {
+			return mover.InProgress(), err
+		}
 	}
 
-	// Allocate cache volume
-	cachePVC, err := m.ensureCache(ctx, dataPVC)
-	if cachePVC == nil || err != nil {
-		return mover.InProgress(), err
+	// Allocate cache volume. In the other modes, it is part of the mover Pod.
+	var cachePVC *v1.PersistentVolumeClaim
+	if m.getCacheMode() == scribev1alpha1.CacheModePersistent {
+		cachePVC, err = m.ensureCache(ctx, dataPVC)
+		if cachePVC == nil || err != nil {
+			return mover.InProgress(), err
+		}
 	}
 
 	// Prepare ServiceAccount
@@ -104,14 +182,44 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 
 	// Validate Repository Secret
 	repo, err := m.validateRepository(ctx)
-	if repo == nil || err != nil {
+	if err != nil {
 		return mover.InProgress(), err
 	}
+	if repo == nil {
+		return mover.RetryAfter(repositoryServerDelay), nil
+	}
+	if err := m.validateCustomCA(ctx); err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Write the backup filters
+	if m.isSource && m.stream == nil {
+		filters, err := m.ensureFilters(ctx)
+		if filters == nil || err != nil {
+			return mover.InProgress(), err
+		}
+	}
+
+	// Don't start a synchronization while the repository is being pruned. A
+	// paused synchronization doesn't use it, so it doesn't hold up the prune.
+	if !m.paused {
+		busy, err := m.repositoryBusy(ctx, m.jobName(), operationSync)
+		if err != nil {
+			return mover.InProgress(), err
+		}
+		if busy {
+			m.logger.Info("waiting for the repository to be released by the prune")
+			return mover.RetryAfter(repositoryBusyDelay), nil
+		}
+	}
 
 	// Start mover Job
 	job, err := m.ensureJob(ctx, cachePVC, dataPVC, sa, repo)
+	if m.failure != nil {
+		return mover.Failed(m.failure), nil
+	}
 	if job == nil || err != nil {
-		return mover.InProgress(), err
+		return mover.InProgressWith(m.progress), err
 	}
 
 	// On the destination, preserve the image and return it
@@ -120,23 +228,127 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 		if image == nil || err != nil {
 			return mover.InProgress(), err
 		}
-		return mover.CompleteWithImage(image), nil
+		m.updateRestoredSnapshot()
+		return mover.CompleteWithImage(image).WithSyncResult(m.syncResult), nil
 	}
 
 	// On the source, just signal completion
-	return mover.Complete(), nil
+	return mover.Complete().WithSyncResult(m.syncResult), nil
+}
+
+func (m *Mover) validateSpec() error {
+	if m.restoreAsOf != nil && m.snapshotID != nil {
+		return fmt.Errorf("%w: only one of restoreAsOf and snapshotID may be specified",
+			mover.ErrInvalidSpec)
+	}
+	if m.repositoryServer != nil && (m.repositoryName != "" || m.repositoryPVC != nil) {
+		return fmt.Errorf("%w: repositoryServer may not be combined with repository or repositoryPVC",
+			mover.ErrInvalidSpec)
+	}
+	if _, err := m.expandRepositoryPath(); err != nil {
+		return err
+	}
+	if _, err := m.parsePruneSchedule(); err != nil {
+		return err
+	}
+	if err := m.validateCustomCASpec(); err != nil {
+		return err
+	}
+	if err := m.validateStaleLockAge(); err != nil {
+		return err
+	}
+	if err := m.validateStream(); err != nil {
+		return err
+	}
+	for _, tag := range m.tags {
+		if tag == "" || strings.Contains(tag, ",") {
+			return fmt.Errorf("%w: invalid tag %q", mover.ErrInvalidSpec, tag)
+		}
+	}
+	return m.validateFilters()
+}
+
+// updateRestoredSnapshot records the snapshot that was restored, as reported
+// by the mover Job, in the status
+func (m *Mover) updateRestoredSnapshot() {
+	if m.syncResult == nil || m.syncResult.SnapshotID == "" {
+		return
+	}
+	m.destStatus.RestoredSnapshot = m.syncResult.SnapshotID
+	m.destStatus.RestoredSnapshotTime = nil
+	if t, err := time.Parse(time.RFC3339Nano, m.syncResult.Details["snapshotTime"]); err == nil {
+		m.destStatus.RestoredSnapshotTime = &metav1.Time{Time: t}
+	}
+}
+
+// restoreEnv returns the environment variables that select the snapshot to be
+// restored
+func (m *Mover) restoreEnv() []v1.EnvVar {
+	var env []v1.EnvVar
+	if m.snapshotID != nil {
+		env = append(env, v1.EnvVar{Name: "SNAPSHOT_ID", Value: *m.snapshotID})
+	}
+	if m.restoreAsOf != nil {
+		env = append(env, v1.EnvVar{Name: "RESTORE_AS_OF", Value: m.restoreAsOf.UTC().Format(time.RFC3339)})
+	}
+	env = append(env, v1.EnvVar{Name: "RESTORE_MODE", Value: string(m.getRestoreMode())})
+	return env
+}
+
+func (m *Mover) getRestoreMode() scribev1alpha1.ResticRestoreMode {
+	if m.restoreMode == nil {
+		return scribev1alpha1.RestoreModeOverlay
+	}
+	return *m.restoreMode
+}
+
+// validateRestoreTarget ensures that a Mirror restore only deletes files from
+// a volume that Scribe provisioned, or one whose owner has allowed it via
+// allowMirrorAnnotation
+func (m *Mover) validateRestoreTarget(pvc *v1.PersistentVolumeClaim) error {
+	if m.getRestoreMode() != scribev1alpha1.RestoreModeMirror {
+		return nil
+	}
+	if metav1.IsControlledBy(pvc, m.owner) || pvc.GetAnnotations()[allowMirrorAnnotation] == "true" {
+		return nil
+	}
+	return fmt.Errorf("%w: restoreMode Mirror deletes files from PVC %s, which was not created by Scribe. "+
+		"Annotate it with %s=true to allow this", mover.ErrInvalidSpec, pvc.Name, allowMirrorAnnotation)
 }
 
 func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
+	result, err := m.cleanup(ctx)
+	return m.withPrune(ctx, result, err)
+}
+
+func (m *Mover) cleanup(ctx context.Context) (mover.Result, error) {
+	if !m.isSource {
+		// Remove the snapshot annotation from the destination PVC so that
+		// the next iteration will take a new snapshot instead of reusing the
+		// one that was just preserved.
+		if err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, m.destinationPVCName()); err != nil {
+			return mover.InProgress(), err
+		}
+	}
 	err := utils.CleanupObjects(ctx, m.client, m.logger, m.owner, cleanupTypes)
 	if err != nil {
 		return mover.InProgress(), err
 	}
+	if err := m.unlockRepository(ctx, m.jobName(), operationSync); err != nil {
+		return mover.InProgress(), err
+	}
+	// Deleting the Job also deletes an Ephemeral cache, but a Persistent one
+	// remains from before the cache mode was changed
+	if m.getCacheMode() != scribev1alpha1.CacheModePersistent {
+		if err := m.removePersistentCache(ctx); err != nil {
+			return mover.InProgress(), err
+		}
+	}
 	return mover.Complete(), nil
 }
 
-func (m *Mover) ensureCache(ctx context.Context,
-	dataPVC *v1.PersistentVolumeClaim) (*v1.PersistentVolumeClaim, error) {
+// cacheVolumeHandler creates the vh used to provision the cache volume
+func (m *Mover) cacheVolumeHandler(dataPVC *v1.PersistentVolumeClaim) (*volumehandler.VolumeHandler, error) {
 	// Create a separate vh for the Restic cache volume that's based on the main
 	// vh, but override options where necessary.
 	cacheConfig := []volumehandler.VHOption{
@@ -155,25 +367,95 @@ func (m *Mover) ensureCache(ctx context.Context,
 	// 1. Directly specified cache accessMode
 	// 2. Directly specified volume accessMode
 	// 3. Inherited from the source/data PVC
+	// 4. ReadWriteOnce, for a stream that has no data PVC
 	if m.cacheAccessModes != nil {
 		cacheConfig = append(cacheConfig, volumehandler.AccessModes(m.cacheAccessModes))
 	} else if len(m.vh.GetAccessModes()) == 0 {
-		cacheConfig = append(cacheConfig, volumehandler.AccessModes(dataPVC.Spec.AccessModes))
+		accessModes := []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+		if dataPVC != nil {
+			accessModes = dataPVC.Spec.AccessModes
+		}
+		cacheConfig = append(cacheConfig, volumehandler.AccessModes(accessModes))
 	}
 
 	if m.cacheStorageClassName != nil {
 		cacheConfig = append(cacheConfig, volumehandler.StorageClassName(m.cacheStorageClassName))
 	}
 
-	cacheVh, err := volumehandler.NewVolumeHandler(cacheConfig...)
+	return volumehandler.NewVolumeHandler(cacheConfig...)
+}
+
+func (m *Mover) cacheName() string {
+	return "scribe-" + m.owner.GetName() + "-cache"
+}
+
+func (m *Mover) getCacheMode() scribev1alpha1.ResticCacheMode {
+	if m.cacheMode == nil {
+		return scribev1alpha1.CacheModePersistent
+	}
+	return *m.cacheMode
+}
+
+func (m *Mover) ensureCache(ctx context.Context,
+	dataPVC *v1.PersistentVolumeClaim) (*v1.PersistentVolumeClaim, error) {
+	cacheVh, err := m.cacheVolumeHandler(dataPVC)
 	if err != nil {
 		return nil, err
 	}
 
 	// Allocate cache volume
-	cacheName := "scribe-" + m.owner.GetName() + "-cache"
-	m.logger.Info("allocating cache volume", "PVC", cacheName)
-	return cacheVh.EnsureNewPVC(ctx, m.logger, cacheName)
+	m.logger.Info("allocating cache volume", "PVC", m.cacheName())
+	return cacheVh.EnsureNewPVC(ctx, m.logger, m.cacheName())
+}
+
+// cacheVolumeSource provides the cache volume for the mover Job according to
+// the cache mode. The cachePVC is only used in the Persistent mode.
+func (m *Mover) cacheVolumeSource(cachePVC *v1.PersistentVolumeClaim,
+	dataPVC *v1.PersistentVolumeClaim) (corev1.VolumeSource, error) {
+	switch m.getCacheMode() {
+	case scribev1alpha1.CacheModeEmptyDir:
+		cacheCapacity := resource.MustParse("1Gi")
+		if m.cacheCapacity != nil {
+			cacheCapacity = *m.cacheCapacity
+		}
+		return corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &cacheCapacity},
+		}, nil
+	case scribev1alpha1.CacheModeEphemeral:
+		cacheVh, err := m.cacheVolumeHandler(dataPVC)
+		if err != nil {
+			return corev1.VolumeSource{}, err
+		}
+		template, err := cacheVh.NewPVCTemplate(m.logger)
+		if err != nil {
+			return corev1.VolumeSource{}, err
+		}
+		return corev1.VolumeSource{
+			Ephemeral: &corev1.EphemeralVolumeSource{VolumeClaimTemplate: template},
+		}, nil
+	default:
+		return corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: cachePVC.Name,
+			},
+		}, nil
+	}
+}
+
+// removePersistentCache deletes the cache PVC that was used by the Persistent
+// cache mode, if it exists
+func (m *Mover) removePersistentCache(ctx context.Context) error {
+	cache := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.cacheName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Delete(ctx, cache)
+	if err == nil {
+		m.logger.Info("removed persistent cache volume", "PVC", m.cacheName())
+	}
+	return client.IgnoreNotFound(err)
 }
 
 func (m *Mover) ensureSourcePVC(ctx context.Context) (*v1.PersistentVolumeClaim, error) {
@@ -190,11 +472,17 @@ func (m *Mover) ensureSourcePVC(ctx context.Context) (*v1.PersistentVolumeClaim,
 	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
 }
 
+func (m *Mover) destinationPVCName() string {
+	if m.mainPVCName != nil {
+		return *m.mainPVCName
+	}
+	return "scribe-" + m.owner.GetName() + "-dest"
+}
+
 func (m *Mover) ensureDestinationPVC(ctx context.Context) (*v1.PersistentVolumeClaim, error) {
 	if m.mainPVCName == nil {
 		// Need to allocate the incoming data volume
-		dataPVCName := "scribe-" + m.owner.GetName() + "-dest"
-		return m.vh.EnsureNewPVC(ctx, m.logger, dataPVCName)
+		return m.vh.EnsureNewPVC(ctx, m.logger, m.destinationPVCName())
 	}
 
 	// use provided PVC
@@ -208,18 +496,31 @@ func (m *Mover) ensureDestinationPVC(ctx context.Context) (*v1.PersistentVolumeC
 	return pvc, err
 }
 
-func (m *Mover) ensureSA(ctx context.Context) (*v1.ServiceAccount, error) {
-	dir := "src"
-	if !m.isSource {
-		dir = "dst"
+// direction returns the string used in object names to identify the side of
+// the relationship.
+func (m *Mover) direction() string {
+	if m.isSource {
+		return "src"
 	}
+	return "dst"
+}
+
+func (m *Mover) jobName() string {
+	return "scribe-" + m.direction() + "-" + m.owner.GetName()
+}
+
+func (m *Mover) ensureSA(ctx context.Context) (*v1.ServiceAccount, error) {
 	sa := &v1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-" + dir + "-" + m.owner.GetName(),
+			Name:      "scribe-" + m.direction() + "-" + m.owner.GetName(),
 			Namespace: m.owner.GetNamespace(),
 		},
 	}
 	saDesc := utils.NewSAHandler(ctx, m.client, m.owner, sa)
+	saDesc.ExtraRules = []rbacv1.PolicyRule{mover.ProgressReporterRule(m.jobName())}
+	if m.isSource && m.pruneSchedule != nil {
+		saDesc.ExtraRules = append(saDesc.ExtraRules, mover.ProgressReporterRule(m.pruneJobName()))
+	}
 	cont, err := saDesc.Reconcile(m.logger)
 	if cont {
 		return sa, err
@@ -227,7 +528,20 @@ func (m *Mover) ensureSA(ctx context.Context) (*v1.ServiceAccount, error) {
 	return nil, err
 }
 
+// validateRepository returns the Secret that describes the repository. It
+// returns nil without an error while waiting for a repository server.
 func (m *Mover) validateRepository(ctx context.Context) (*v1.Secret, error) {
+	if m.repositoryServer != nil {
+		secret, err := m.validateRepositoryServer(ctx)
+		if secret == nil || err != nil {
+			return nil, err
+		}
+		if err := m.resolveRepository(secret); err != nil {
+			return nil, err
+		}
+		return secret, nil
+	}
+
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      m.repositoryName,
@@ -235,156 +549,265 @@ func (m *Mover) validateRepository(ctx context.Context) (*v1.Secret, error) {
 		},
 	}
 	logger := m.logger.WithValues("repositorySecret", utils.NameFor(secret))
-	if err := utils.GetAndValidateSecret(ctx, m.client, logger, secret,
-		"RESTIC_REPOSITORY", "RESTIC_PASSWORD"); err != nil {
+	// A repository on a PVC is located by the mover, so only the password is
+	// needed
+	fields := []string{"RESTIC_REPOSITORY", "RESTIC_PASSWORD"}
+	if m.repositoryPVC != nil {
+		fields = []string{"RESTIC_PASSWORD"}
+	}
+	if err := utils.GetAndValidateSecret(ctx, m.client, logger, secret, fields...); err != nil {
 		logger.Error(err, "Restic config secret does not contain the proper fields")
 		return nil, err
 	}
+	if err := m.validateRepositoryPVC(ctx); err != nil {
+		return nil, err
+	}
+	if err := m.resolveRepository(secret); err != nil {
+		return nil, err
+	}
 	return secret, nil
 }
 
 //nolint:funlen
 func (m *Mover) ensureJob(ctx context.Context, cachePVC *v1.PersistentVolumeClaim,
 	dataPVC *v1.PersistentVolumeClaim, sa *v1.ServiceAccount, repo *v1.Secret) (*batchv1.Job, error) {
-	dir := "src"
-	if !m.isSource {
-		dir = "dst"
-	}
-
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-" + dir + "-" + m.owner.GetName(),
+			Name:      m.jobName(),
 			Namespace: m.owner.GetNamespace(),
 		},
 	}
 	logger := m.logger.WithValues("job", utils.NameFor(job))
-	_, err := ctrlutil.CreateOrUpdate(ctx, m.client, job, func() error {
+	deleted, err := mover.CreateOrUpdateJob(ctx, m.client, logger, job, func() error {
 		if err := ctrl.SetControllerReference(m.owner, job, m.client.Scheme()); err != nil {
 			logger.Error(err, "unable to set controller reference")
 			return err
 		}
 		utils.MarkForCleanup(m.owner, job)
+		m.labelJob(job, operationSync)
 		job.Spec.Template.ObjectMeta.Name = job.Name
-		backoffLimit := int32(8)
+		backoffLimit := int32(2)
 		job.Spec.BackoffLimit = &backoffLimit
 		parallelism := int32(1)
 		if m.paused {
 			parallelism = int32(0)
 		}
 		job.Spec.Parallelism = &parallelism
-		forgetOptions := generateForgetOptions(m.retainPolicy)
-		runAsUser := int64(0)
-
-		var actions []string
-		if m.isSource {
-			actions = []string{"backup"}
-			if m.shouldPrune(time.Now()) {
-				actions = append(actions, "prune")
-			}
+
+		actions, retireKey := m.jobActions(job, repo)
+
+		cacheVolume, err := m.cacheVolumeSource(cachePVC, dataPVC)
+		if err != nil {
+			logger.Error(err, "unable to configure the cache volume")
+			return err
+		}
+		m.configureMoverPod(job, sa, repo, cacheVolume, actions)
+
+		container := &job.Spec.Template.Spec.Containers[0]
+		if dataPVC != nil {
+			container.VolumeMounts = append(container.VolumeMounts,
+				corev1.VolumeMount{Name: dataVolumeName, MountPath: mountPath})
+			job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes,
+				v1.Volume{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: dataPVC.Name,
+					}},
+				})
+		}
+		if m.isSource && m.stream == nil {
+			container.VolumeMounts = append(container.VolumeMounts,
+				corev1.VolumeMount{Name: filtersVolumeName, MountPath: filtersMountPath})
+			job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes,
+				v1.Volume{Name: filtersVolumeName, VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: m.filtersName()},
+					}},
+				})
 		} else {
-			actions = []string{"restore"}
+			container.Env = append(container.Env, m.restoreEnv()...)
 		}
-		logger.Info("job actions", "actions", actions)
-
-		job.Spec.Template.Spec.Containers = []v1.Container{{
-			Name: "restic",
-			Env: []v1.EnvVar{
-				{Name: "FORGET_OPTIONS", Value: forgetOptions},
-				{Name: "DATA_DIR", Value: mountPath},
-				{Name: "RESTIC_CACHE_DIR", Value: resticCacheMountPath},
-				// We populate environment variables from the restic repo
-				// Secret. They are taken 1-for-1 from the Secret into env vars.
-				// The allowed variables are defined by restic.
-				// https://restic.readthedocs.io/en/stable/040_backup.html#environment-variables
-				// Mandatory variables are needed to define the repository
-				// location and its password.
-				utils.EnvFromSecret(repo.Name, "RESTIC_REPOSITORY", false),
-				utils.EnvFromSecret(repo.Name, "RESTIC_PASSWORD", false),
-				// Optional variables based on what backend is used for restic
-				utils.EnvFromSecret(repo.Name, "AWS_ACCESS_KEY_ID", true),
-				utils.EnvFromSecret(repo.Name, "AWS_SECRET_ACCESS_KEY", true),
-				utils.EnvFromSecret(repo.Name, "AWS_DEFAULT_REGION", true),
-				utils.EnvFromSecret(repo.Name, "ST_AUTH", true),
-				utils.EnvFromSecret(repo.Name, "ST_USER", true),
-				utils.EnvFromSecret(repo.Name, "ST_KEY", true),
-				utils.EnvFromSecret(repo.Name, "OS_AUTH_URL", true),
-				utils.EnvFromSecret(repo.Name, "OS_REGION_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USERNAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USER_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_PASSWORD", true),
-				utils.EnvFromSecret(repo.Name, "OS_TENANT_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_TENANT_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USER_DOMAIN_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USER_DOMAIN_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_PROJECT_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_PROJECT_DOMAIN_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_PROJECT_DOMAIN_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_TRUST_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_APPLICATION_CREDENTIAL_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_APPLICATION_CREDENTIAL_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_APPLICATION_CREDENTIAL_SECRET", true),
-				utils.EnvFromSecret(repo.Name, "OS_STORAGE_URL", true),
-				utils.EnvFromSecret(repo.Name, "OS_AUTH_TOKEN", true),
-				utils.EnvFromSecret(repo.Name, "B2_ACCOUNT_ID", true),
-				utils.EnvFromSecret(repo.Name, "B2_ACCOUNT_KEY", true),
-				utils.EnvFromSecret(repo.Name, "AZURE_ACCOUNT_NAME", true),
-				utils.EnvFromSecret(repo.Name, "AZURE_ACCOUNT_KEY", true),
-				utils.EnvFromSecret(repo.Name, "GOOGLE_PROJECT_ID", true),
-				utils.EnvFromSecret(repo.Name, "GOOGLE_APPLICATION_CREDENTIALS", true),
-			},
-			Command: []string{"/entry.sh"},
-			Args:    actions,
-			Image:   resticContainerImage,
-			SecurityContext: &corev1.SecurityContext{
-				RunAsUser: &runAsUser,
-			},
-			VolumeMounts: []corev1.VolumeMount{
-				{Name: dataVolumeName, MountPath: mountPath},
-				{Name: resticCache, MountPath: resticCacheMountPath},
-			},
-		}}
-		job.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyNever
-		job.Spec.Template.Spec.ServiceAccountName = sa.Name
-		job.Spec.Template.Spec.Volumes = []v1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: dataPVC.Name,
-				}},
-			},
-			{Name: resticCache, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: cachePVC.Name,
-				}},
-			},
+		if retireKey != "" {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "RETIRE_KEY", Value: retireKey})
 		}
+		if m.stream != nil {
+			m.configureStream(job, dataPVC)
+		}
+		mover.ApplyMoverConfig(&job.Spec.Template.Spec, m.moverConfig)
+		m.pinToRepositoryNode(job)
 		return nil
 	})
+	if deleted {
+		return nil, err
+	}
+	m.updateLocks(job)
 	// If Job had failed, delete it so it can be recreated
 	if job.Status.Failed >= *job.Spec.BackoffLimit {
-		logger.Info("deleting job -- backoff limit reached")
+		failure := mover.JobFailure(ctx, m.client, job)
+		logger.Info("deleting job -- backoff limit reached", "reason", failure.Reason)
 		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err == nil {
+			m.failure = failure
+		}
 		return nil, err
 	}
 	if err != nil {
 		logger.Error(err, "reconcile failed")
 	}
 
+	if m.isSource {
+		m.updateKeyRotation(job, repo)
+	}
+
 	// Stop here if the job hasn't completed yet
 	if job.Status.Succeeded == 0 {
+		m.progress = mover.ProgressFromJob(job)
 		return nil, nil
 	}
 
 	logger.Info("job completed")
-	if m.isSource && m.shouldPrune(time.Now()) {
+	m.syncResult = mover.SyncResultFromJob(ctx, m.client, job)
+	if m.isSource {
+		m.updateSnapshots(repositoryFromJob(job))
+	}
+	if m.isSource && jobHasAction(job, "prune") {
 		now := metav1.Now()
 		m.sourceStatus.LastPruned = &now
 		logger.Info("prune completed", ".Status.Restic.LastPruned", m.sourceStatus.LastPruned)
 	}
+	if m.isSource && jobHasAction(job, "check") {
+		m.updateCheck(checkFromJob(job))
+	}
 	// We only continue reconciling if the restic job has completed
 	return job, nil
 }
 
+// jobActions decides the operations that the Job performs and, for retire-key,
+// the key to remove. Since the pod template of a Job can't be changed, they
+// are decided when the Job is created and taken from the Job after that.
+func (m *Mover) jobActions(job *batchv1.Job, repo *v1.Secret) ([]string, string) {
+	if !job.CreationTimestamp.IsZero() && len(job.Spec.Template.Spec.Containers) > 0 {
+		container := job.Spec.Template.Spec.Containers[0]
+		retireKey := ""
+		for _, env := range container.Env {
+			if env.Name == "RETIRE_KEY" {
+				retireKey = env.Value
+			}
+		}
+		return container.Args, retireKey
+	}
+
+	if !m.isSource {
+		if m.stream != nil {
+			return []string{"restore-stream"}, ""
+		}
+		return []string{"restore"}, ""
+	}
+	actions := []string{"backup"}
+	if m.stream != nil {
+		actions = []string{"backup-stream"}
+	}
+	retireKey := ""
+	if m.shouldRotateKey(repo) {
+		actions = append([]string{"rotate-key"}, actions...)
+	} else if m.shouldRetireKey() {
+		actions = append([]string{"retire-key"}, actions...)
+		retireKey = m.sourceStatus.KeyRotation.OldKeyID
+	}
+	if m.shouldPrune(time.Now()) {
+		actions = append(actions, "prune")
+	}
+	if m.shouldCheck(time.Now()) {
+		actions = append(actions, "check")
+	}
+	return actions, retireKey
+}
+
+// configureMoverPod sets up the restic container and the volumes that are
+// common to all of the mover's Jobs
+func (m *Mover) configureMoverPod(job *batchv1.Job, sa *v1.ServiceAccount, repo *v1.Secret,
+	cacheVolume corev1.VolumeSource, actions []string) {
+	forgetOptions := generateForgetOptions(m.retainPolicy)
+	if m.appendOnly {
+		// Snapshots can't be forgotten
+		forgetOptions = ""
+	}
+	// Already validated by validateSpec()
+	repositoryPath, _ := m.expandRepositoryPath()
+	runAsUser := int64(0)
+
+	job.Spec.Template.Spec.Containers = []v1.Container{{
+		Name: "restic",
+		Env: append(mover.ProgressEnv(job), []v1.EnvVar{
+			{Name: "FORGET_OPTIONS", Value: forgetOptions},
+			{Name: "RESTIC_HOST", Value: m.hostname},
+			{Name: "REPOSITORY_PATH", Value: repositoryPath},
+			{Name: "STALE_LOCK_AGE", Value: m.staleLockAgeOption()},
+			{Name: "RESTIC_TAGS", Value: strings.Join(m.tags, ",")},
+			{Name: "SNAPSHOT_REPORT_LIMIT", Value: strconv.Itoa(maxStatusSnapshots)},
+			{Name: "READ_DATA_SUBSET", Value: m.readDataSubsetOption()},
+			{Name: "DATA_DIR", Value: mountPath},
+			{Name: "RESTIC_CACHE_DIR", Value: resticCacheMountPath},
+			// We populate environment variables from the restic repo
+			// Secret. They are taken 1-for-1 from the Secret into env vars.
+			// Mandatory variables are needed to define the repository
+			// location and its password. Any others (e.g., for the
+			// chosen backend) are passed through from the Secret via
+			// EnvFrom.
+			// https://restic.readthedocs.io/en/stable/040_backup.html#environment-variables
+			m.repositoryEnv(repo),
+			utils.EnvFromSecret(repo.Name, "RESTIC_PASSWORD", false),
+		}...),
+		EnvFrom: []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: repo.Name},
+			},
+		}},
+		Command: []string{"/entry.sh"},
+		Args:    actions,
+		Image:   resticContainerImage,
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser: &runAsUser,
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: resticCache, MountPath: resticCacheMountPath},
+		},
+	}}
+	job.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyNever
+	job.Spec.Template.Spec.ServiceAccountName = sa.Name
+	job.Spec.Template.Spec.Volumes = []v1.Volume{
+		{Name: resticCache, VolumeSource: cacheVolume},
+	}
+	if m.repositoryPVC != nil {
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes,
+			v1.Volume{Name: repositoryVolumeName, VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: *m.repositoryPVC,
+				}},
+			})
+		container := &job.Spec.Template.Spec.Containers[0]
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: repositoryVolumeName, MountPath: repositoryMountPath,
+		})
+	}
+	if m.customCA != nil {
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes,
+			v1.Volume{Name: customCAVolumeName, VolumeSource: m.customCAVolumeSource()})
+		container := &job.Spec.Template.Spec.Containers[0]
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name: customCAVolumeName, MountPath: customCAMountPath, ReadOnly: true,
+		})
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: "CUSTOM_CA", Value: path.Join(customCAMountPath, customCAFilename),
+		})
+	}
+}
+
 func (m *Mover) shouldPrune(current time.Time) bool {
+	// Scheduled prunes run in their own Job, and an append-only repository
+	// can't be pruned
+	if m.pruneSchedule != nil || m.appendOnly {
+		return false
+	}
 	delta := time.Hour * 24 * 7 // default prune every 7 days
 	if m.pruneInterval != nil {
 		delta = time.Hour * 24 * time.Duration(*m.pruneInterval)
@@ -397,6 +820,14 @@ func (m *Mover) shouldPrune(current time.Time) bool {
 	return current.After(lastPruned.Add(delta))
 }
 
+// readDataSubsetOption is the portion of the data to verify during a check
+func (m *Mover) readDataSubsetOption() string {
+	if m.readDataSubset == nil {
+		return ""
+	}
+	return *m.readDataSubset
+}
+
 func generateForgetOptions(policy *scribev1alpha1.ResticRetainPolicy) string {
 	const defaultForget = "--keep-last 1"
 
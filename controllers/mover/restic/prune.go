@@ -0,0 +1,450 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	cron "github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+	"github.com/backube/scribe/controllers/utils"
+)
+
+const (
+	// repositoryLabel identifies the repository that is used by a mover Job
+	// so that Jobs which must not overlap can find each other, even across
+	// namespaces. Its value is derived from the location of the repository.
+	repositoryLabel = "scribe.backube/restic-repository-id"
+	// operationLabel is the kind of work that a mover Job performs in the
+	// repository, either operationSync or operationPrune
+	operationLabel = "scribe.backube/restic-operation"
+	operationSync  = "sync"
+	operationPrune = "prune"
+	// Values of .status.restic.prune.lastPruneResult
+	pruneResultInProgress = "InProgress"
+	pruneResultSucceeded  = "Succeeded"
+	pruneResultFailed     = "Failed"
+	// repositoryBusyDelay is how long to wait before checking again whether
+	// the repository is still in use by another Job
+	repositoryBusyDelay = time.Minute
+)
+
+var (
+	pruneDurations = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "restic_prune_duration_seconds",
+			Namespace:  mover.MetricsNamespace,
+			Help:       "Duration of the scheduled Restic prunes in seconds",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+			MaxAge:     24 * time.Hour,
+		},
+		repositoryMetricLabels,
+	)
+	pruneFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "restic_prune_failures_total",
+			Namespace: mover.MetricsNamespace,
+			Help:      "The number of scheduled Restic prunes that have failed",
+		},
+		repositoryMetricLabels,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(pruneDurations, pruneFailures)
+}
+
+func (m *Mover) pruneJobName() string {
+	return "scribe-prune-" + m.owner.GetName()
+}
+
+// parsePruneSchedule parses the pruneSchedule. It returns nil if prunes are
+// not scheduled.
+func (m *Mover) parsePruneSchedule() (cron.Schedule, error) {
+	if m.pruneSchedule == nil {
+		return nil, nil
+	}
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	schedule, err := parser.Parse(*m.pruneSchedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid pruneSchedule %q: %v",
+			mover.ErrInvalidSpec, *m.pruneSchedule, err)
+	}
+	return schedule, nil
+}
+
+// nextPruneTime is when the next scheduled prune should start: the first
+// scheduled time following the most recent prune (or the creation of the
+// ReplicationSource if there hasn't been one)
+func (m *Mover) nextPruneTime(schedule cron.Schedule) time.Time {
+	last := m.owner.GetCreationTimestamp().Time
+	if !m.sourceStatus.LastPruned.IsZero() {
+		last = m.sourceStatus.LastPruned.Time
+	}
+	if prune := m.sourceStatus.Prune; prune != nil &&
+		!prune.LastPruneStartTime.IsZero() && prune.LastPruneStartTime.After(last) {
+		last = prune.LastPruneStartTime.Time
+	}
+	return schedule.Next(last)
+}
+
+// repositoryID identifies the repository as a label value
+func (m *Mover) repositoryID() string {
+	repository := ""
+	if m.isSource {
+		repository = m.sourceStatus.Repository
+	} else {
+		repository = m.destStatus.Repository
+	}
+	sum := sha256.Sum256([]byte(repository))
+	return hex.EncodeToString(sum[:16])
+}
+
+// labelJob marks a Job with the repository that it uses and the operation
+// that it performs
+func (m *Mover) labelJob(job *batchv1.Job, operation string) {
+	labels := job.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[repositoryLabel] = m.repositoryID()
+	labels[operationLabel] = operation
+	job.SetLabels(labels)
+}
+
+// jobRunning determines whether a Job may still be using the repository
+func jobRunning(job *batchv1.Job) bool {
+	if job.Status.Active > 0 {
+		return true
+	}
+	if job.Status.Succeeded > 0 || job.DeletionTimestamp != nil {
+		return false
+	}
+	if job.Spec.BackoffLimit != nil && job.Status.Failed >= *job.Spec.BackoffLimit {
+		return false
+	}
+	// A paused Job doesn't hold the repository
+	return job.Spec.Parallelism == nil || *job.Spec.Parallelism > 0
+}
+
+// repositoryInUse determines whether the repository is being used by a Job
+// that performs the operation. Once the Job named jobName has been created,
+// the repository is no longer considered to be in use so that the Job is
+// able to run to completion.
+func (m *Mover) repositoryInUse(ctx context.Context, jobName string, operation string) (bool, error) {
+	own := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Get(ctx, utils.NameFor(own), own)
+	if err == nil {
+		return false, nil
+	} else if !kerrors.IsNotFound(err) {
+		return false, err
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := m.client.List(ctx, jobs, client.MatchingLabels{
+		repositoryLabel: m.repositoryID(),
+		operationLabel:  operation,
+	}); err != nil {
+		return false, err
+	}
+	for i := range jobs.Items {
+		if jobRunning(&jobs.Items[i]) {
+			m.logger.Info("repository is in use", "job", utils.NameFor(&jobs.Items[i]))
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// withPrune reconciles the scheduled prune alongside the synchronization or
+// cleanup that produced result. The prune is tracked independently of the sync
+// cycle, so a long prune doesn't hold up the next synchronization; only its
+// request to be reconciled again is merged into the result.
+func (m *Mover) withPrune(ctx context.Context, result mover.Result, err error) (mover.Result, error) {
+	if !m.isSource || err != nil {
+		return result, err
+	}
+	retryAfter, err := m.reconcilePrune(ctx)
+	if retryAfter != nil {
+		if *retryAfter < 0 {
+			*retryAfter = 0
+		}
+		if result.RetryAfter == nil || *retryAfter < *result.RetryAfter {
+			result.RetryAfter = retryAfter
+		}
+	}
+	return result, err
+}
+
+// reconcilePrune runs the prune Job once it is due. It returns how long to
+// wait before reconciling the prune again, or nil if the prune Job will
+// trigger it.
+//
+//nolint:funlen
+func (m *Mover) reconcilePrune(ctx context.Context) (*time.Duration, error) {
+	schedule, err := m.parsePruneSchedule()
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		// The pruneSchedule may have just been removed
+		if m.sourceStatus.Prune != nil {
+			if err := m.removePruneJob(ctx); err != nil {
+				return nil, err
+			}
+			if err := m.unlockRepository(ctx, m.pruneJobName(), operationPrune); err != nil {
+				return nil, err
+			}
+			m.sourceStatus.Prune = nil
+		}
+		return nil, nil
+	}
+	if err := m.validateSpec(); err != nil {
+		return nil, err
+	}
+	if m.sourceStatus.Prune == nil {
+		m.sourceStatus.Prune = &scribev1alpha1.ResticPruneStatus{}
+	}
+	pruneStatus := m.sourceStatus.Prune
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.pruneJobName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err = m.client.Get(ctx, utils.NameFor(job), job)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	if kerrors.IsNotFound(err) {
+		next := m.nextPruneTime(schedule)
+		pruneStatus.NextPruneTime = &metav1.Time{Time: next}
+		if delay := time.Until(next); delay > 0 {
+			return &delay, nil
+		}
+	} else if job.DeletionTimestamp != nil {
+		// Wait for the previous prune to be removed
+		return nil, nil
+	}
+
+	// The source PVC only provides the defaults for the cache volume, and a
+	// stream may not have one
+	var srcPVC *corev1.PersistentVolumeClaim
+	if m.needsSourcePVC() {
+		srcPVC = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      *m.mainPVCName,
+				Namespace: m.owner.GetNamespace(),
+			},
+		}
+		if err := m.client.Get(ctx, utils.NameFor(srcPVC), srcPVC); err != nil {
+			return nil, err
+		}
+	}
+	var cachePVC *corev1.PersistentVolumeClaim
+	if m.getCacheMode() == scribev1alpha1.CacheModePersistent {
+		cachePVC, err = m.ensureCache(ctx, srcPVC)
+		if cachePVC == nil || err != nil {
+			return nil, err
+		}
+	}
+	sa, err := m.ensureSA(ctx)
+	if sa == nil || err != nil {
+		return nil, err
+	}
+	repo, err := m.validateRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		delay := repositoryServerDelay
+		return &delay, nil
+	}
+	if m.appendOnly {
+		return nil, fmt.Errorf("%w: pruneSchedule may not be used with an append-only repository server",
+			mover.ErrInvalidSpec)
+	}
+	if err := m.validateCustomCA(ctx); err != nil {
+		return nil, err
+	}
+
+	// Don't start pruning while a synchronization is using the repository. A
+	// paused prune doesn't use it, so it doesn't hold up the synchronizations.
+	if !m.paused {
+		if busy, err := m.repositoryBusy(ctx, m.pruneJobName(), operationPrune); busy || err != nil {
+			delay := repositoryBusyDelay
+			return &delay, err
+		}
+	}
+
+	job, err = m.ensurePruneJob(ctx, cachePVC, srcPVC, sa, repo)
+	if job == nil || err != nil {
+		return nil, err
+	}
+	if err := m.unlockRepository(ctx, m.pruneJobName(), operationPrune); err != nil {
+		return nil, err
+	}
+	next := m.nextPruneTime(schedule)
+	pruneStatus.NextPruneTime = &metav1.Time{Time: next}
+	delay := time.Until(next)
+	return &delay, nil
+}
+
+// repositoryBusy acquires the repository lock for the Job, and checks that the
+// Jobs in other Namespaces, which the lock doesn't cover, aren't using the
+// repository in a way that conflicts with the operation.
+func (m *Mover) repositoryBusy(ctx context.Context, jobName string, operation string) (bool, error) {
+	locked, err := m.lockRepository(ctx, jobName, operation)
+	if !locked || err != nil {
+		return true, err
+	}
+	conflicting := operationPrune
+	if operation == operationPrune {
+		conflicting = operationSync
+	}
+	return m.repositoryInUse(ctx, jobName, conflicting)
+}
+
+// ensurePruneJob starts or continues the prune Job. It returns the Job once it
+// has finished and has been removed.
+func (m *Mover) ensurePruneJob(ctx context.Context, cachePVC *corev1.PersistentVolumeClaim,
+	srcPVC *corev1.PersistentVolumeClaim, sa *corev1.ServiceAccount, repo *corev1.Secret) (*batchv1.Job, error) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.pruneJobName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("job", utils.NameFor(job))
+	deleted, err := mover.CreateOrUpdateJob(ctx, m.client, logger, job, func() error {
+		if err := ctrl.SetControllerReference(m.owner, job, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		m.labelJob(job, operationPrune)
+		job.Spec.Template.ObjectMeta.Name = job.Name
+		backoffLimit := int32(2)
+		job.Spec.BackoffLimit = &backoffLimit
+		parallelism := int32(1)
+		if m.paused {
+			parallelism = int32(0)
+		}
+		job.Spec.Parallelism = &parallelism
+
+		cacheVolume, err := m.cacheVolumeSource(cachePVC, srcPVC)
+		if err != nil {
+			logger.Error(err, "unable to configure the cache volume")
+			return err
+		}
+		m.configureMoverPod(job, sa, repo, cacheVolume, []string{"prune"})
+		mover.ApplyMoverConfig(&job.Spec.Template.Spec, m.moverConfig)
+		m.pinToRepositoryNode(job)
+		return nil
+	})
+	if deleted {
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(err, "reconcile failed")
+		return nil, err
+	}
+	m.updateLocks(job)
+
+	pruneStatus := m.sourceStatus.Prune
+	if pruneStatus.LastPruneStartTime == nil || !pruneStatus.LastPruneStartTime.Equal(&job.CreationTimestamp) {
+		logger.Info("prune started")
+		start := job.CreationTimestamp
+		pruneStatus.LastPruneStartTime = &start
+		pruneStatus.LastPruneResult = pruneResultInProgress
+		pruneStatus.Message = ""
+		pruneStatus.NextPruneTime = nil
+	}
+
+	var failure *mover.Failure
+	if job.Status.Failed >= *job.Spec.BackoffLimit {
+		failure = mover.JobFailure(ctx, m.client, job)
+		logger.Info("prune failed -- backoff limit reached", "reason", failure.Reason)
+	} else if job.Status.Succeeded == 0 {
+		// Stop here if the job hasn't finished yet
+		return nil, nil
+	}
+
+	if pruneStatus.LastPruneResult == pruneResultInProgress {
+		m.recordPrune(job, failure)
+	}
+	err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// recordPrune records the outcome of a finished prune Job in the status and
+// metrics
+func (m *Mover) recordPrune(job *batchv1.Job, failure *mover.Failure) {
+	now := metav1.Now()
+	pruneStatus := m.sourceStatus.Prune
+	duration := now.Sub(job.CreationTimestamp.Time)
+	pruneStatus.LastPruneDuration = &metav1.Duration{Duration: duration}
+	labels := prometheus.Labels{
+		"obj_name":      m.owner.GetName(),
+		"obj_namespace": m.owner.GetNamespace(),
+	}
+	if failure != nil {
+		pruneStatus.LastPruneResult = pruneResultFailed
+		pruneStatus.Message = failure.Reason + ": " + failure.Message
+		pruneFailures.With(labels).Inc()
+		return
+	}
+	pruneStatus.LastPruneResult = pruneResultSucceeded
+	m.sourceStatus.LastPruned = &now
+	pruneDurations.With(labels).Observe(duration.Seconds())
+	m.logger.Info("prune completed", ".Status.Restic.LastPruned", m.sourceStatus.LastPruned)
+}
+
+// removePruneJob deletes the prune Job, if it exists
+func (m *Mover) removePruneJob(ctx context.Context) error {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.pruneJobName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	return client.IgnoreNotFound(err)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/backube/scribe/controllers/mover"
+	"github.com/backube/scribe/controllers/utils"
+)
+
+const (
+	customCAVolumeName   = "custom-ca"
+	customCAMountPath    = "/customCA"
+	customCAFilename     = "ca.crt"
+	repositoryVolumeName = "repository"
+	repositoryMountPath  = "/repository"
+	// repositoryNodeAnnotation records the node that a mover Job is pinned to
+	// because its ReadWriteOnce repository PVC was attached there when the
+	// Job was created
+	repositoryNodeAnnotation = "scribe.backube/restic-repository-node"
+)
+
+// urlCredentials matches the user information portion of a URL
+var urlCredentials = regexp.MustCompile(`://[^/@]*@`)
+
+// expandRepositoryPath fills in the placeholders of the repositoryPath
+// template. It returns "" if there is no template.
+func (m *Mover) expandRepositoryPath() (string, error) {
+	if m.repositoryPath == nil {
+		return "", nil
+	}
+	expanded := strings.NewReplacer(
+		"{{namespace}}", m.owner.GetNamespace(),
+		"{{name}}", m.owner.GetName(),
+	).Replace(*m.repositoryPath)
+	expanded = strings.Trim(expanded, "/")
+	if strings.Contains(expanded, "{{") || strings.Contains(expanded, "}}") {
+		return "", fmt.Errorf("%w: unknown placeholder in repositoryPath %q",
+			mover.ErrInvalidSpec, *m.repositoryPath)
+	}
+	if expanded == "" || hasParentRef(expanded) || strings.ContainsAny(expanded, "\r\n") {
+		return "", fmt.Errorf("%w: invalid repositoryPath %q",
+			mover.ErrInvalidSpec, *m.repositoryPath)
+	}
+	return expanded, nil
+}
+
+// resolveRepository determines the location of the repository from the
+// repository Secret and the repositoryPath, recording it in the status
+func (m *Mover) resolveRepository(secret *corev1.Secret) error {
+	path, err := m.expandRepositoryPath()
+	if err != nil {
+		return err
+	}
+	repository := string(secret.Data["RESTIC_REPOSITORY"])
+	if m.repositoryPVC != nil {
+		repository = "pvc:" + *m.repositoryPVC
+	}
+	if path != "" {
+		repository = strings.TrimRight(repository, "/") + "/" + path
+	}
+
+	// Don't publish any credentials that are embedded in the URL
+	repository = urlCredentials.ReplaceAllString(repository, "://")
+	if m.isSource {
+		m.sourceStatus.Repository = repository
+	} else {
+		m.destStatus.Repository = repository
+	}
+	return nil
+}
+
+// validateRepositoryPVC ensures that the volume holding the repository exists
+// so that the mover is able to start. Unless the volume is ReadWriteMany, it
+// also finds the node where it is attached.
+func (m *Mover) validateRepositoryPVC(ctx context.Context) error {
+	if m.repositoryPVC == nil {
+		return nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      *m.repositoryPVC,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := m.client.Get(ctx, utils.NameFor(pvc), pvc); err != nil {
+		m.logger.Error(err, "failed to get repository PVC", "PVC", utils.NameFor(pvc))
+		return err
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		if mode == corev1.ReadWriteMany {
+			return nil
+		}
+	}
+
+	// The volume is attached to the node of any pod that is using it
+	pods := &corev1.PodList{}
+	if err := m.client.List(ctx, pods, client.InNamespace(pvc.Namespace)); err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded ||
+			pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvc.Name {
+				m.repositoryNode = pod.Spec.NodeName
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// pinToRepositoryNode requires the mover pod to run on the node where the
+// ReadWriteOnce repository PVC was attached when the Job was created, so that
+// it doesn't wait for the volume to be released by the other pods using it.
+// The pod template can't be changed later, so the node is recorded on the Job.
+// It must be called after ApplyMoverConfig().
+func (m *Mover) pinToRepositoryNode(job *batchv1.Job) {
+	if job.CreationTimestamp.IsZero() && m.repositoryNode != "" {
+		annotations := job.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[repositoryNodeAnnotation] = m.repositoryNode
+		job.SetAnnotations(annotations)
+	}
+	node, ok := job.GetAnnotations()[repositoryNodeAnnotation]
+	if !ok {
+		return
+	}
+	// The node is added to each of the alternative terms of the moverConfig
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      "metadata.name",
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{node},
+	}
+	podSpec := &job.Spec.Template.Spec
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := podSpec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{}},
+		}
+	}
+	terms := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for i := range terms {
+		terms[i].MatchFields = append(terms[i].MatchFields, requirement)
+	}
+}
+
+// repositoryEnv provides RESTIC_REPOSITORY to the mover, either from the
+// repository Secret or as the mount point of the repositoryPVC
+func (m *Mover) repositoryEnv(secret *corev1.Secret) corev1.EnvVar {
+	if m.repositoryPVC != nil {
+		return corev1.EnvVar{Name: "RESTIC_REPOSITORY", Value: repositoryMountPath}
+	}
+	return utils.EnvFromSecret(secret.Name, "RESTIC_REPOSITORY", false)
+}
+
+// validateCustomCASpec ensures the CA bundle is referenced correctly
+func (m *Mover) validateCustomCASpec() error {
+	if m.customCA == nil {
+		return nil
+	}
+	if (m.customCA.SecretName == "") == (m.customCA.ConfigMapName == "") {
+		return fmt.Errorf("%w: customCA must specify exactly one of secretName and configMapName",
+			mover.ErrInvalidSpec)
+	}
+	if m.customCA.Key == "" {
+		return fmt.Errorf("%w: customCA must specify a key", mover.ErrInvalidSpec)
+	}
+	return nil
+}
+
+// validateCustomCA ensures the referenced CA bundle exists so that the mover
+// is able to start
+func (m *Mover) validateCustomCA(ctx context.Context) error {
+	if m.customCA == nil {
+		return nil
+	}
+	if m.customCA.SecretName != "" {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      m.customCA.SecretName,
+				Namespace: m.owner.GetNamespace(),
+			},
+		}
+		logger := m.logger.WithValues("customCASecret", utils.NameFor(secret))
+		return utils.GetAndValidateSecret(ctx, m.client, logger, secret, m.customCA.Key)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.customCA.ConfigMapName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("customCAConfigMap", utils.NameFor(cm))
+	if err := m.client.Get(ctx, utils.NameFor(cm), cm); err != nil {
+		logger.Error(err, "failed to get ConfigMap with provided name")
+		return err
+	}
+	if _, ok := cm.Data[m.customCA.Key]; !ok {
+		if _, ok := cm.BinaryData[m.customCA.Key]; !ok {
+			err := fmt.Errorf("configmap is missing field: %v", m.customCA.Key)
+			logger.Error(err, "configmap does not contain the CA bundle")
+			return err
+		}
+	}
+	return nil
+}
+
+// customCAVolumeSource provides the CA bundle to the mover Job as the file
+// customCAFilename
+func (m *Mover) customCAVolumeSource() corev1.VolumeSource {
+	items := []corev1.KeyToPath{{Key: m.customCA.Key, Path: customCAFilename}}
+	if m.customCA.SecretName != "" {
+		return corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: m.customCA.SecretName,
+				Items:      items,
+			},
+		}
+	}
+	return corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: m.customCA.ConfigMapName},
+			Items:                items,
+		},
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/backube/scribe/controllers/utils"
+)
+
+// repositoryLockGracePeriod is how long the holder of the repository lock has
+// to start its Job. After that, the lock is considered abandoned once the
+// holder's Job is no longer running.
+const repositoryLockGracePeriod = 5 * time.Minute
+
+// repositoryLockName is the ConfigMap that serializes the Jobs using the
+// repository within the Namespace. Each of its keys is a holder of the lock,
+// naming the operation and the Job that performs it, and its value is when the
+// lock was acquired.
+func (m *Mover) repositoryLockName() string {
+	return "scribe-restic-lock-" + m.repositoryID()
+}
+
+func lockHolder(jobName string, operation string) string {
+	return operation + "." + jobName
+}
+
+// splitLockHolder returns the operation and the Job name of a lock holder
+func splitLockHolder(holder string) (string, string) {
+	parts := strings.SplitN(holder, ".", 2)
+	if len(parts) != 2 {
+		return holder, ""
+	}
+	return parts[0], parts[1]
+}
+
+// lockRepository acquires the repository lock for the Job, returning true once
+// the Job may use the repository. Synchronizations share the lock, but a prune
+// requires it exclusively: a synchronization can't acquire it while a prune
+// holds it, and a prune that acquires it waits for the synchronizations that
+// already hold it to finish. The lock is only changed by creating or updating
+// its ConfigMap, so two Jobs that try to acquire it at the same time can't
+// both succeed; the loser gets a conflict and tries again later.
+//
+//nolint:funlen
+func (m *Mover) lockRepository(ctx context.Context, jobName string, operation string) (bool, error) {
+	holder := lockHolder(jobName, operation)
+	now := time.Now().UTC().Format(time.RFC3339)
+	lock := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.repositoryLockName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Get(ctx, utils.NameFor(lock), lock)
+	if kerrors.IsNotFound(err) {
+		lock.Labels = map[string]string{repositoryLabel: m.repositoryID()}
+		lock.Data = map[string]string{holder: now}
+		// The lock is removed with the last of the objects that use it
+		if err := ctrlutil.SetOwnerReference(m.owner, lock, m.client.Scheme()); err != nil {
+			return false, err
+		}
+		err = m.client.Create(ctx, lock)
+		if kerrors.IsAlreadyExists(err) {
+			m.logger.Info("repository lock was created concurrently")
+			return false, nil
+		}
+		return err == nil, err
+	} else if err != nil {
+		return false, err
+	}
+
+	if lock.Data == nil {
+		lock.Data = make(map[string]string)
+	}
+	changed := false
+	var pruning, syncing []string
+	for other, since := range lock.Data {
+		if other == holder {
+			continue
+		}
+		abandoned, err := m.lockAbandoned(ctx, other, since)
+		if err != nil {
+			return false, err
+		}
+		if abandoned {
+			m.logger.Info("removing abandoned repository lock", "holder", other)
+			delete(lock.Data, other)
+			changed = true
+		} else if op, _ := splitLockHolder(other); op == operationPrune {
+			pruning = append(pruning, other)
+		} else {
+			syncing = append(syncing, other)
+		}
+	}
+	_, acquired := lock.Data[holder]
+	if !acquired && len(pruning) == 0 {
+		lock.Data[holder] = now
+		if err := ctrlutil.SetOwnerReference(m.owner, lock, m.client.Scheme()); err != nil {
+			return false, err
+		}
+		acquired, changed = true, true
+	}
+	if changed {
+		err = m.client.Update(ctx, lock)
+		if kerrors.IsConflict(err) {
+			m.logger.Info("repository lock was modified concurrently")
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+	}
+	if !acquired {
+		m.logger.Info("repository is locked", "holders", pruning)
+		return false, nil
+	}
+	if operation == operationPrune && len(syncing) > 0 {
+		m.logger.Info("waiting for the repository to be released", "holders", syncing)
+		return false, nil
+	}
+	return true, nil
+}
+
+// lockAbandoned determines whether the holder of the repository lock has
+// stopped using the repository without releasing it (e.g., because its owner
+// was deleted)
+func (m *Mover) lockAbandoned(ctx context.Context, holder string, since string) (bool, error) {
+	if acquired, err := time.Parse(time.RFC3339, since); err == nil &&
+		time.Since(acquired) < repositoryLockGracePeriod {
+		return false, nil
+	}
+	_, jobName := splitLockHolder(holder)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Get(ctx, utils.NameFor(job), job)
+	if kerrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return !jobRunning(job), nil
+}
+
+// unlockRepository releases the Job's hold on the repository lock. The lock is
+// deleted once it has no holders left.
+func (m *Mover) unlockRepository(ctx context.Context, jobName string, operation string) error {
+	lock := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.repositoryLockName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := m.client.Get(ctx, utils.NameFor(lock), lock); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	holder := lockHolder(jobName, operation)
+	if _, ok := lock.Data[holder]; !ok {
+		return nil
+	}
+	delete(lock.Data, holder)
+	if len(lock.Data) > 0 {
+		return m.client.Update(ctx, lock)
+	}
+	// Keep the lock if another Job has acquired it in the meantime
+	resourceVersion := lock.ResourceVersion
+	err := m.client.Delete(ctx, lock, client.Preconditions{ResourceVersion: &resourceVersion})
+	return client.IgnoreNotFound(err)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+	"github.com/backube/scribe/controllers/utils"
+)
+
+// repositoryServerDelay is how often to check whether a ResticRepositoryServer
+// has made the repository available
+const repositoryServerDelay = 30 * time.Second
+
+// validateRepositoryServer finds the credentials that were generated for the
+// repository on the ResticRepositoryServer. It returns nil until the server
+// is able to accept them.
+func (m *Mover) validateRepositoryServer(ctx context.Context) (*corev1.Secret, error) {
+	server := &scribev1alpha1.ResticRepositoryServer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.repositoryServer.Name,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("repositoryServer", utils.NameFor(server))
+	if err := m.client.Get(ctx, utils.NameFor(server), server); err != nil {
+		logger.Error(err, "failed to get ResticRepositoryServer")
+		return nil, err
+	}
+	// Snapshots can't be removed from an append-only repository
+	m.appendOnly = server.Spec.AppendOnly
+
+	repository := utils.RepositoryName(m.owner, m.repositoryServer)
+	if server.Status == nil || !server.Status.Conditions.IsTrueFor(scribev1alpha1.ConditionServerAvailable) ||
+		!containsString(server.Status.Repositories, repository) {
+		logger.Info("waiting for the server to provide the repository", "repository", repository)
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.RepositoryCredentialsName(server.Name, repository),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := utils.GetAndValidateSecret(ctx, m.client, logger, secret,
+		"RESTIC_REPOSITORY", "RESTIC_PASSWORD"); err != nil {
+		return nil, err
+	}
+	if secret.Labels[utils.RepositoryServerLabel] != server.Name ||
+		secret.Labels[utils.RepositoryLabel] != repository {
+		return nil, fmt.Errorf("%w: secret %v does not hold the credentials of repository %v on %v",
+			mover.ErrInvalidSpec, secret.Name, repository, server.Name)
+	}
+	return secret, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+)
+
+const (
+	// repositoryAnnotation is the annotation that a backup Job places on
+	// itself to report the contents of the repository once the retain policy
+	// has been applied. Its value is a JSON-encoded repositoryReport.
+	repositoryAnnotation = "scribe.backube/restic-repository"
+	// maxStatusSnapshots is the number of snapshots listed in the status
+	maxStatusSnapshots = 20
+)
+
+// repositoryReport is the summary of the repository's contents reported by a
+// backup Job
+type repositoryReport struct {
+	// SnapshotCount is the number of snapshots in the repository
+	SnapshotCount *int64 `json:"snapshotCount,omitempty"`
+	// TotalSize is the amount of data, in bytes, stored in the repository for
+	// the source's snapshots
+	TotalSize *int64 `json:"totalSize,omitempty"`
+	// Snapshots are the most recent snapshots in the repository
+	Snapshots []struct {
+		ID   string    `json:"id"`
+		Time time.Time `json:"time"`
+		Tags []string  `json:"tags,omitempty"`
+	} `json:"snapshots,omitempty"`
+}
+
+var (
+	repositoryMetricLabels = []string{
+		"obj_name",      // Name of the ReplicationSource
+		"obj_namespace", // Namespace containing the ReplicationSource
+	}
+
+	repositorySize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "restic_repository_size_bytes",
+			Namespace: mover.MetricsNamespace,
+			Help:      "The amount of data stored in the Restic repository for the source's snapshots, in bytes",
+		},
+		repositoryMetricLabels,
+	)
+	repositorySnapshots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "restic_repository_snapshots",
+			Namespace: mover.MetricsNamespace,
+			Help:      "The number of snapshots in the Restic repository",
+		},
+		repositoryMetricLabels,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(repositorySize, repositorySnapshots)
+}
+
+// repositoryFromJob retrieves the report of the repository's contents from a
+// completed backup Job. It returns nil if there is no (valid) report.
+func repositoryFromJob(job *batchv1.Job) *repositoryReport {
+	value, ok := job.GetAnnotations()[repositoryAnnotation]
+	if !ok {
+		return nil
+	}
+	report := &repositoryReport{}
+	if err := json.Unmarshal([]byte(value), report); err != nil {
+		return nil
+	}
+	return report
+}
+
+// updateSnapshots records the contents of the repository in the status and
+// metrics following a backup.
+func (m *Mover) updateSnapshots(report *repositoryReport) {
+	if report == nil {
+		return
+	}
+
+	// Restic doesn't provide the size of each snapshot, so it is taken from
+	// the backup's result and remembered for as long as the snapshot is listed
+	sizes := map[string]*int64{}
+	for _, snap := range m.sourceStatus.Snapshots {
+		sizes[snap.ID] = snap.Size
+	}
+	if m.syncResult != nil && m.syncResult.SnapshotID != "" {
+		sizes[m.syncResult.SnapshotID] = m.syncResult.BytesTotal
+	}
+
+	snapshots := []scribev1alpha1.ResticSnapshot{}
+	for _, snap := range report.Snapshots {
+		snapshots = append(snapshots, scribev1alpha1.ResticSnapshot{
+			ID:   snap.ID,
+			Time: metav1.Time{Time: snap.Time},
+			Size: sizes[snap.ID],
+			Tags: snap.Tags,
+		})
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[j].Time.Before(&snapshots[i].Time)
+	})
+	if len(snapshots) > maxStatusSnapshots {
+		snapshots = snapshots[:maxStatusSnapshots]
+	}
+	m.sourceStatus.Snapshots = snapshots
+
+	labels := prometheus.Labels{
+		"obj_name":      m.owner.GetName(),
+		"obj_namespace": m.owner.GetNamespace(),
+	}
+	if report.TotalSize != nil {
+		repositorySize.With(labels).Set(float64(*report.TotalSize))
+	}
+	if report.SnapshotCount != nil {
+		repositorySnapshots.With(labels).Set(float64(*report.SnapshotCount))
+	}
+}
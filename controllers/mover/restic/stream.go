@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package restic
+
+import (
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+)
+
+const (
+	streamContainerName   = "stream"
+	streamVolumeName      = "stream"
+	streamMountPath       = "/stream"
+	defaultStreamFilename = "stdin"
+	// noTokenVolumeName is an empty volume that is mounted over the
+	// ServiceAccount token's location in the stream container. The token is
+	// only mounted into containers that don't already have a volume there, so
+	// the command doesn't get the mover's permission to update its Job.
+	noTokenVolumeName = "no-sa-token"
+	noTokenMountPath  = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// streamScript runs the stream command (its arguments) in the stream
+// container. The restic container creates the FIFO "pipe" in the shared
+// volume when it is ready for the stream, and it creates "done" when it exits.
+// The command's exit status is written to "status" so that the restic
+// container can tell a complete stream from a truncated one. The placeholder
+// is the redirection that connects the command to the FIFO.
+const streamScript = `
+until [ -p /stream/pipe ]; do
+  if [ -e /stream/done ]; then
+    echo "the restic container exited before the stream started"
+    exit 1
+  fi
+  sleep 1
+done
+"$@" %s /stream/pipe
+rc=$?
+echo "$rc" > /stream/status
+exit "$rc"
+`
+
+// streamFilename is the name of the stream within the snapshots
+func (m *Mover) streamFilename() string {
+	if m.stream == nil || m.stream.Filename == nil {
+		return defaultStreamFilename
+	}
+	return *m.stream.Filename
+}
+
+// hasStreamCommand is true if the stream is produced or consumed by a command
+// in its own container
+func (m *Mover) hasStreamCommand() bool {
+	return m.stream != nil && len(m.stream.Command) > 0
+}
+
+// needsSourcePVC is false for a stream that isn't read from a volume
+func (m *Mover) needsSourcePVC() bool {
+	return m.stream == nil || (m.mainPVCName != nil && *m.mainPVCName != "")
+}
+
+func (m *Mover) validateStream() error {
+	if m.stream == nil {
+		return nil
+	}
+	filename := m.streamFilename()
+	if filename == "" || filename == "." || filename == ".." || strings.ContainsAny(filename, "/\r\n") {
+		return fmt.Errorf("%w: invalid stream filename %q", mover.ErrInvalidSpec, filename)
+	}
+	if m.isSource && !m.hasStreamCommand() {
+		return fmt.Errorf("%w: a stream requires a command on a ReplicationSource", mover.ErrInvalidSpec)
+	}
+	if m.hasStreamCommand() && m.stream.Image == "" {
+		return fmt.Errorf("%w: a stream command requires an image", mover.ErrInvalidSpec)
+	}
+	if len(m.include) > 0 || len(m.exclude) > 0 || m.excludeCaches || len(m.excludeIfPresent) > 0 {
+		return fmt.Errorf("%w: the include and exclude filters may not be combined with a stream",
+			mover.ErrInvalidSpec)
+	}
+	if m.getRestoreMode() == scribev1alpha1.RestoreModeMirror {
+		return fmt.Errorf("%w: restoreMode Mirror may not be combined with a stream", mover.ErrInvalidSpec)
+	}
+	return nil
+}
+
+// configureStream adds the stream command's container to the mover Job,
+// along with the volume that holds the FIFO connecting it to restic
+func (m *Mover) configureStream(job *batchv1.Job, dataPVC *corev1.PersistentVolumeClaim) {
+	podSpec := &job.Spec.Template.Spec
+	restic := &podSpec.Containers[0]
+	restic.Env = append(restic.Env, corev1.EnvVar{Name: "STREAM_FILENAME", Value: m.streamFilename()})
+	if !m.hasStreamCommand() {
+		// The stream is restored to a file of the data volume
+		return
+	}
+
+	restic.Env = append(restic.Env, corev1.EnvVar{Name: "STREAM_DIR", Value: streamMountPath})
+	restic.VolumeMounts = append(restic.VolumeMounts,
+		corev1.VolumeMount{Name: streamVolumeName, MountPath: streamMountPath})
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         streamVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+
+	redirection := "<"
+	if m.isSource {
+		redirection = ">"
+	}
+	container := corev1.Container{
+		Name:  streamContainerName,
+		Image: m.stream.Image,
+		Command: append([]string{"/bin/sh", "-c", fmt.Sprintf(streamScript, redirection), streamContainerName},
+			m.stream.Command...),
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: streamVolumeName, MountPath: streamMountPath},
+			{Name: noTokenVolumeName, MountPath: noTokenMountPath, ReadOnly: true},
+		},
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         noTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	if m.stream.Resources != nil {
+		container.Resources = *m.stream.Resources
+	}
+	if dataPVC != nil {
+		container.VolumeMounts = append(container.VolumeMounts,
+			corev1.VolumeMount{Name: dataVolumeName, MountPath: mountPath})
+	}
+	podSpec.Containers = append(podSpec.Containers, container)
+}
@@ -0,0 +1,535 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rsync
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+	"github.com/backube/scribe/controllers/utils"
+	"github.com/backube/scribe/controllers/volumehandler"
+)
+
+const (
+	mountPath      = "/data"
+	dataVolumeName = "data"
+	keysVolumeName = "keys"
+	keysMountPath  = "/keys"
+)
+
+// Mover is the reconciliation logic for the Rsync-based data mover.
+type Mover struct {
+	client      client.Client
+	logger      logr.Logger
+	owner       metav1.Object
+	vh          *volumehandler.VolumeHandler
+	sshKeys     *string
+	serviceType *corev1.ServiceType
+	address     *string
+	port        *int32
+	sshUser     *string
+	path        *string
+	isSource    bool
+	paused      bool
+	moverConfig *scribev1alpha1.MoverConfig
+	mainPVCName *string
+	// progress is the most recent progress report from the mover Job
+	progress *scribev1alpha1.SyncProgress
+	// failure is the cause of a failed mover Job that has been discarded
+	failure *mover.Failure
+	// syncResult is the result reported by the completed mover Job
+	syncResult *scribev1alpha1.SyncResult
+	// Only one of these will be set, depending on the direction
+	sourceStatus *scribev1alpha1.ReplicationSourceRsyncStatus
+	destStatus   *scribev1alpha1.ReplicationDestinationRsyncStatus
+}
+
+var _ mover.Mover = &Mover{}
+
+// All object types that are temporary/per-iteration should be listed here. The
+// individual objects to be cleaned up must also be marked.
+var cleanupTypes = []client.Object{
+	&corev1.PersistentVolumeClaim{},
+	&snapv1.VolumeSnapshot{},
+	&batchv1.Job{},
+}
+
+func (m *Mover) Name() string { return "rsync" }
+
+func (m *Mover) Requirements() mover.Requirements {
+	return mover.Requirements{
+		CopyMethod:  m.vh.GetCopyMethod(),
+		ServiceType: m.serviceType,
+		Secret:      m.sshKeys,
+	}
+}
+
+func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
+	var err error
+	// Allocate temporary data PVC
+	var dataPVC *corev1.PersistentVolumeClaim
+	if m.isSource {
+		dataPVC, err = m.ensureSourcePVC(ctx)
+	} else {
+		dataPVC, err = m.ensureDestinationPVC(ctx)
+	}
+	if dataPVC == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Ensure service (if required) and publish the address in the status
+	cont, err := m.ensureServiceAndPublishAddress(ctx)
+	if !cont || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Ensure Secrets/keys
+	rsyncSecretName, err := m.ensureSecrets(ctx)
+	if rsyncSecretName == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Prepare ServiceAccount
+	sa, err := m.ensureSA(ctx)
+	if sa == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Start mover Job
+	job, err := m.ensureJob(ctx, dataPVC, sa, *rsyncSecretName)
+	if m.failure != nil {
+		return mover.Failed(m.failure), nil
+	}
+	if job == nil || err != nil {
+		return mover.InProgressWith(m.progress), err
+	}
+
+	// On the destination, preserve the image and return it
+	if !m.isSource {
+		image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
+		if image == nil || err != nil {
+			return mover.InProgress(), err
+		}
+		return mover.CompleteWithImage(image).WithSyncResult(m.syncResult), nil
+	}
+
+	// On the source, just signal completion
+	return mover.Complete().WithSyncResult(m.syncResult), nil
+}
+
+func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
+	if !m.isSource {
+		// Remove the snapshot annotation from the destination PVC so that
+		// the next iteration will take a new snapshot instead of reusing the
+		// one that was just preserved.
+		if err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, m.destinationPVCName()); err != nil {
+			return mover.InProgress(), err
+		}
+	}
+	err := utils.CleanupObjects(ctx, m.client, m.logger, m.owner, cleanupTypes)
+	if err != nil {
+		return mover.InProgress(), err
+	}
+	return mover.Complete(), nil
+}
+
+// direction returns the string used in object names to identify the side of
+// the relationship.
+func (m *Mover) direction() string {
+	if m.isSource {
+		return "src"
+	}
+	return "dest"
+}
+
+// serverEnd returns which side of the relationship runs sshd and accepts the
+// connection from its peer. Whichever side has been given an address connects
+// out to the other. Normally, the source pushes to the destination, but if the
+// destination has an address, it pulls from the source instead.
+func (m *Mover) serverEnd() string {
+	if m.isSource == (m.address != nil) {
+		return "DESTINATION"
+	}
+	return "SOURCE"
+}
+
+// namePrefix is the prefix for the names of the per-CR objects (Job, Service,
+// ServiceAccount, and generated Secrets).
+func (m *Mover) namePrefix() string {
+	return "scribe-rsync-" + m.direction()
+}
+
+func (m *Mover) jobName() string {
+	return m.namePrefix() + "-" + m.owner.GetName()
+}
+
+func (m *Mover) destinationPVCName() string {
+	if m.mainPVCName != nil {
+		return *m.mainPVCName
+	}
+	return "scribe-dest-" + m.owner.GetName()
+}
+
+func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	srcPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      *m.mainPVCName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := m.client.Get(ctx, utils.NameFor(srcPVC), srcPVC); err != nil {
+		return nil, err
+	}
+	dataName := "scribe-src-" + m.owner.GetName()
+	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
+}
+
+func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	if m.mainPVCName == nil {
+		// Need to allocate the incoming data volume
+		return m.vh.EnsureNewPVC(ctx, m.logger, m.destinationPVCName())
+	}
+
+	// use provided PVC
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      *m.mainPVCName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	err := m.client.Get(ctx, utils.NameFor(pvc), pvc)
+	return pvc, err
+}
+
+func (m *Mover) updateStatusAddress(address *string) {
+	if m.isSource {
+		m.sourceStatus.Address = address
+	} else {
+		m.destStatus.Address = address
+	}
+}
+
+func (m *Mover) updateStatusSSHKeys(secretName *string) {
+	if m.isSource {
+		m.sourceStatus.SSHKeys = secretName
+	} else {
+		m.destStatus.SSHKeys = secretName
+	}
+}
+
+func (m *Mover) serviceSelector() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      m.direction() + "-" + m.owner.GetName(),
+		"app.kubernetes.io/component": "rsync-mover",
+		"app.kubernetes.io/part-of":   "scribe",
+	}
+}
+
+// ensureServiceAndPublishAddress maintains the Service that is used to connect
+// to the rsync mover and records its address in the status. No Service is
+// necessary if the connection will be outbound.
+func (m *Mover) ensureServiceAndPublishAddress(ctx context.Context) (bool, error) {
+	if m.address != nil {
+		// Connection will be outbound. Don't need a Service
+		m.updateStatusAddress(nil)
+		return true, nil
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.namePrefix() + "-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := m.ensureService(ctx, service); err != nil {
+		return false, err
+	}
+
+	address := getServiceAddress(service)
+	if address == "" {
+		// We don't have an address yet, try again later
+		m.updateStatusAddress(nil)
+		return false, nil
+	}
+	m.updateStatusAddress(&address)
+
+	m.logger.V(1).Info("Service addr published", "address", address)
+	return true, nil
+}
+
+func (m *Mover) ensureService(ctx context.Context, service *corev1.Service) error {
+	logger := m.logger.WithValues("service", utils.NameFor(service))
+
+	op, err := ctrlutil.CreateOrUpdate(ctx, m.client, service, func() error {
+		if err := ctrl.SetControllerReference(m.owner, service, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+
+		if service.ObjectMeta.Annotations == nil {
+			service.ObjectMeta.Annotations = map[string]string{}
+		}
+		service.ObjectMeta.Annotations["service.beta.kubernetes.io/aws-load-balancer-type"] = "nlb"
+
+		if m.serviceType != nil {
+			service.Spec.Type = *m.serviceType
+		} else {
+			service.Spec.Type = corev1.ServiceTypeClusterIP
+		}
+		service.Spec.Selector = m.serviceSelector()
+		if len(service.Spec.Ports) != 1 {
+			service.Spec.Ports = []corev1.ServicePort{{}}
+		}
+		service.Spec.Ports[0].Name = "ssh"
+		if m.port != nil {
+			service.Spec.Ports[0].Port = *m.port
+		} else {
+			service.Spec.Ports[0].Port = 22
+		}
+		service.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+		service.Spec.Ports[0].TargetPort = intstr.FromInt(22)
+		if service.Spec.Type == corev1.ServiceTypeClusterIP {
+			service.Spec.Ports[0].NodePort = 0
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Service reconcile failed")
+		return err
+	}
+
+	logger.V(1).Info("Service reconciled", "operation", op)
+	return nil
+}
+
+func getServiceAddress(svc *corev1.Service) string {
+	address := svc.Spec.ClusterIP
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		if len(svc.Status.LoadBalancer.Ingress) > 0 {
+			if svc.Status.LoadBalancer.Ingress[0].Hostname != "" {
+				address = svc.Status.LoadBalancer.Ingress[0].Hostname
+			} else if svc.Status.LoadBalancer.Ingress[0].IP != "" {
+				address = svc.Status.LoadBalancer.Ingress[0].IP
+			}
+		} else {
+			address = ""
+		}
+	}
+	return address
+}
+
+// ensureSecrets returns the name of the Secret that holds the ssh keys to be
+// used by this side's mover Job. If the user provided keys, those are
+// validated and used. Otherwise, a set of keys is generated and the name of
+// the Secret for the other side is published in the status.
+func (m *Mover) ensureSecrets(ctx context.Context) (*string, error) {
+	// If user provided keys, use those
+	if m.sshKeys != nil {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      *m.sshKeys,
+				Namespace: m.owner.GetNamespace(),
+			},
+		}
+		fields := []string{"source", "source.pub", "destination.pub"}
+		if !m.isSource {
+			fields = []string{"destination", "destination.pub", "source.pub"}
+		}
+		logger := m.logger.WithValues("sshKeysSecret", utils.NameFor(secret))
+		if err := utils.GetAndValidateSecret(ctx, m.client, logger, secret, fields...); err != nil {
+			logger.Error(err, "SSH keys secret does not contain the proper fields")
+			return nil, err
+		}
+		return &secret.Name, nil
+	}
+
+	// otherwise, we need to create our own
+	keyInfo := sshKeys{
+		Context:      ctx,
+		Client:       m.client,
+		Owner:        m.owner,
+		NameTemplate: m.namePrefix(),
+	}
+	cont, err := keyInfo.Reconcile(m.logger)
+	if !cont || err != nil {
+		m.updateStatusSSHKeys(nil)
+		return nil, err
+	}
+	if m.isSource {
+		m.updateStatusSSHKeys(&keyInfo.DestSecret.Name)
+		return &keyInfo.SrcSecret.Name, nil
+	}
+	m.updateStatusSSHKeys(&keyInfo.SrcSecret.Name)
+	return &keyInfo.DestSecret.Name, nil
+}
+
+func (m *Mover) ensureSA(ctx context.Context) (*corev1.ServiceAccount, error) {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.namePrefix() + "-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	saDesc := utils.NewSAHandler(ctx, m.client, m.owner, sa)
+	saDesc.ExtraRules = []rbacv1.PolicyRule{mover.ProgressReporterRule(m.jobName())}
+	cont, err := saDesc.Reconcile(m.logger)
+	if cont {
+		return sa, err
+	}
+	return nil, err
+}
+
+//nolint:funlen
+func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
+	sa *corev1.ServiceAccount, rsyncSecretName string) (*batchv1.Job, error) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.jobName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("job", utils.NameFor(job))
+
+	deleted, err := mover.CreateOrUpdateJob(ctx, m.client, logger, job, func() error {
+		if err := ctrl.SetControllerReference(m.owner, job, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		utils.MarkForCleanup(m.owner, job)
+		job.Spec.Template.ObjectMeta.Name = job.Name
+		if job.Spec.Template.ObjectMeta.Labels == nil {
+			job.Spec.Template.ObjectMeta.Labels = map[string]string{}
+		}
+		for k, v := range m.serviceSelector() {
+			job.Spec.Template.ObjectMeta.Labels[k] = v
+		}
+		backoffLimit := int32(2)
+		job.Spec.BackoffLimit = &backoffLimit
+		parallelism := int32(1)
+		if m.paused {
+			parallelism = int32(0)
+		}
+		job.Spec.Parallelism = &parallelism
+
+		// The connection info describes the end that runs sshd. The client
+		// uses it to connect, and the server uses the user & path to set up
+		// the account that accepts the incoming connection.
+		prefix := m.serverEnd() + "_"
+		containerEnv := mover.ProgressEnv(job)
+		if m.address != nil {
+			containerEnv = append(containerEnv,
+				corev1.EnvVar{Name: prefix + "ADDRESS", Value: *m.address})
+			if m.port != nil {
+				containerEnv = append(containerEnv,
+					corev1.EnvVar{Name: prefix + "PORT", Value: strconv.Itoa(int(*m.port))})
+			}
+		}
+		if m.sshUser != nil {
+			containerEnv = append(containerEnv,
+				corev1.EnvVar{Name: prefix + "USER", Value: *m.sshUser})
+		}
+		if m.path != nil {
+			containerEnv = append(containerEnv,
+				corev1.EnvVar{Name: prefix + "PATH", Value: *m.path})
+		}
+		command := []string{"/bin/bash", "-c", "/destination.sh"}
+		if m.isSource {
+			command = []string{"/bin/bash", "-c", "/source.sh"}
+		}
+		runAsUser := int64(0)
+		job.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:    "rsync",
+			Env:     containerEnv,
+			Command: command,
+			Image:   rsyncContainerImage,
+			SecurityContext: &corev1.SecurityContext{
+				Capabilities: &corev1.Capabilities{
+					Add: []corev1.Capability{
+						"AUDIT_WRITE",
+						"SYS_CHROOT",
+					},
+				},
+				RunAsUser: &runAsUser,
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: dataVolumeName, MountPath: mountPath},
+				{Name: keysVolumeName, MountPath: keysMountPath},
+			},
+		}}
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		job.Spec.Template.Spec.ServiceAccountName = sa.Name
+		secretMode := int32(0600)
+		job.Spec.Template.Spec.Volumes = []corev1.Volume{
+			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dataPVC.Name,
+					ReadOnly:  false,
+				}},
+			},
+			{Name: keysVolumeName, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  rsyncSecretName,
+					DefaultMode: &secretMode,
+				}},
+			},
+		}
+		mover.ApplyMoverConfig(&job.Spec.Template.Spec, m.moverConfig)
+		return nil
+	})
+	if deleted {
+		return nil, err
+	}
+	// If Job had failed, delete it so it can be recreated
+	if job.Status.Failed >= *job.Spec.BackoffLimit {
+		failure := mover.JobFailure(ctx, m.client, job)
+		logger.Info("deleting job -- backoff limit reached", "reason", failure.Reason)
+		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err == nil {
+			m.failure = failure
+		}
+		return nil, err
+	}
+	if err != nil {
+		logger.Error(err, "reconcile failed")
+		return nil, err
+	}
+
+	// Stop here if the job hasn't completed yet
+	if job.Status.Succeeded == 0 {
+		m.progress = mover.ProgressFromJob(job)
+		return nil, nil
+	}
+
+	logger.Info("job completed")
+	m.syncResult = mover.SyncResultFromJob(ctx, m.client, job)
+	// We only continue reconciling if the rsync job has completed
+	return job, nil
+}
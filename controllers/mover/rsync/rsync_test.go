@@ -0,0 +1,532 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rsync
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+)
+
+var _ = Describe("Rsync properly registers", func() {
+	When("Rsync's registration function is called", func() {
+		BeforeEach(func() {
+			Register()
+		})
+		It("is added to the mover catalog", func() {
+			found := false
+			for _, v := range mover.Catalog {
+				if _, ok := v.(*Builder); ok {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Rsync ignores other movers", func() {
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	When("An RS isn't for rsync", func() {
+		It("is ignored", func() {
+			rs := &scribev1alpha1.ReplicationSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cr",
+					Namespace: "blah",
+				},
+				Spec: scribev1alpha1.ReplicationSourceSpec{
+					Rsync: nil,
+				},
+			}
+			builder := Builder{}
+			m, e := builder.FromSource(k8sClient, logger, rs)
+			Expect(m).To(BeNil())
+			Expect(e).NotTo(HaveOccurred())
+		})
+	})
+	When("An RD isn't for rsync", func() {
+		It("is ignored", func() {
+			rd := &scribev1alpha1.ReplicationDestination{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "x",
+					Namespace: "y",
+				},
+				Spec: scribev1alpha1.ReplicationDestinationSpec{
+					Rsync: nil,
+				},
+			}
+			builder := Builder{}
+			m, e := builder.FromDestination(k8sClient, logger, rd)
+			Expect(m).To(BeNil())
+			Expect(e).NotTo(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Rsync as a source", func() {
+	var ctx = context.TODO()
+	var ns *v1.Namespace
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	var rs *scribev1alpha1.ReplicationSource
+	var sPVC *v1.PersistentVolumeClaim
+	var mover *Mover
+	BeforeEach(func() {
+		// Create namespace for test
+		ns = &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "rsync-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		Expect(ns.Name).NotTo(BeEmpty())
+
+		sPVC = &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "s",
+				Namespace: ns.Name,
+			},
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{
+					v1.ReadWriteOnce,
+				},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						"storage": resource.MustParse("7Gi"),
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, sPVC)).To(Succeed())
+
+		// Scaffold ReplicationSource
+		rs = &scribev1alpha1.ReplicationSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rs",
+				Namespace: ns.Name,
+			},
+			Spec: scribev1alpha1.ReplicationSourceSpec{
+				SourcePVC: sPVC.Name,
+				Trigger:   &scribev1alpha1.ReplicationSourceTriggerSpec{},
+				Rsync:     &scribev1alpha1.ReplicationSourceRsyncSpec{},
+				Paused:    false,
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, rs)).To(Succeed())
+		// Controller sets status to non-nil
+		rs.Status = &scribev1alpha1.ReplicationSourceStatus{}
+		// Instantiate an rsync mover for the tests
+		b := Builder{}
+		m, err := b.FromSource(k8sClient, logger, rs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m).NotTo(BeNil())
+		mover, _ = m.(*Mover)
+		Expect(mover).NotTo(BeNil())
+	})
+	AfterEach(func() {
+		// All resources are namespaced, so this should clean it all up
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
+	})
+
+	It("creates the rsync status", func() {
+		Expect(rs.Status.Rsync).NotTo(BeNil())
+	})
+
+	When("a remote address is specified", func() {
+		BeforeEach(func() {
+			address := "my.remote.host"
+			port := int32(2222)
+			rs.Spec.Rsync.Address = &address
+			rs.Spec.Rsync.Port = &port
+		})
+		It("does not need a Service", func() {
+			cont, err := mover.ensureServiceAndPublishAddress(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cont).To(BeTrue())
+			Expect(rs.Status.Rsync.Address).To(BeNil())
+			svc := &v1.Service{}
+			nsn := types.NamespacedName{Name: "scribe-rsync-src-" + rs.Name, Namespace: ns.Name}
+			Consistently(func() error {
+				return k8sClient.Get(ctx, nsn, svc)
+			}, "2s", "500ms").ShouldNot(Succeed())
+		})
+	})
+
+	When("no remote address is specified", func() {
+		var sa *v1.ServiceAccount
+		BeforeEach(func() {
+			path := "/some/dir"
+			rs.Spec.Rsync.Path = &path
+			sa = &v1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thesa",
+					Namespace: ns.Name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, sa)).To(Succeed())
+		})
+		It("creates a ClusterIP Service and publishes its address", func() {
+			cont, err := mover.ensureServiceAndPublishAddress(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cont).To(BeTrue())
+			svc := &v1.Service{}
+			nsn := types.NamespacedName{Name: "scribe-rsync-src-" + rs.Name, Namespace: ns.Name}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, nsn, svc)
+			}, "5s", "1s").Should(Succeed())
+			Expect(svc.Spec.Type).To(Equal(v1.ServiceTypeClusterIP))
+			Expect(rs.Status.Rsync.Address).NotTo(BeNil())
+			Expect(*rs.Status.Rsync.Address).To(Equal(svc.Spec.ClusterIP))
+		})
+		It("waits for the destination to pull", func() {
+			_, err := mover.ensureJob(ctx, sPVC, sa, "thesecret")
+			Expect(err).NotTo(HaveOccurred())
+			job := &batchv1.Job{}
+			nsn := types.NamespacedName{Name: "scribe-rsync-src-" + rs.Name, Namespace: ns.Name}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, nsn, job)
+			}, "5s", "1s").Should(Succeed())
+			c := job.Spec.Template.Spec.Containers[0]
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "SOURCE_PATH", Value: "/some/dir"}))
+			for _, env := range c.Env {
+				Expect(env.Name).NotTo(Equal("DESTINATION_ADDRESS"))
+			}
+		})
+	})
+
+	When("ssh keys are provided", func() {
+		var secret *v1.Secret
+		BeforeEach(func() {
+			secret = &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "keys",
+					Namespace: ns.Name,
+				},
+				StringData: map[string]string{
+					"source":          "foo",
+					"source.pub":      "bar",
+					"destination.pub": "baz",
+				},
+			}
+			rs.Spec.Rsync.SSHKeys = &secret.Name
+		})
+		JustBeforeEach(func() {
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+		})
+		It("uses them directly", func() {
+			var name *string
+			Eventually(func() error {
+				var err error
+				name, err = mover.ensureSecrets(ctx)
+				return err
+			}, "5s", "1s").Should(Succeed())
+			Expect(name).NotTo(BeNil())
+			Expect(*name).To(Equal(secret.Name))
+		})
+		When("the Secret is missing fields", func() {
+			BeforeEach(func() {
+				delete(secret.StringData, "source")
+			})
+			It("is an error", func() {
+				name, err := mover.ensureSecrets(ctx)
+				Expect(err).To(HaveOccurred())
+				Expect(name).To(BeNil())
+			})
+		})
+	})
+
+	Context("mover Job is handled properly", func() {
+		var sa *v1.ServiceAccount
+		var job *batchv1.Job
+		var nsn types.NamespacedName
+		BeforeEach(func() {
+			sa = &v1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thesa",
+					Namespace: ns.Name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, sa)).To(Succeed())
+			address := "my.remote.host"
+			rs.Spec.Rsync.Address = &address
+		})
+		JustBeforeEach(func() {
+			rsyncContainerImage = "thecontainerimage"
+			// hardcoded since we don't get access unless the job is
+			// completed
+			nsn = types.NamespacedName{Name: "scribe-rsync-src-" + rs.Name, Namespace: ns.Name}
+			j, e := mover.ensureJob(ctx, sPVC, sa, "thesecret")
+			Expect(e).NotTo(HaveOccurred())
+			Expect(j).To(BeNil()) // hasn't completed
+			job = &batchv1.Job{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, nsn, job)
+			}, "5s", "1s").Should(Succeed())
+		})
+		It("uses the specified container image, SA, and Secret", func() {
+			Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+			c := job.Spec.Template.Spec.Containers[0]
+			Expect(c.Image).To(Equal(rsyncContainerImage))
+			Expect(c.Command).To(ContainElement("/source.sh"))
+			Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal(sa.Name))
+			found := false
+			for _, vol := range job.Spec.Template.Spec.Volumes {
+				if vol.Secret != nil && vol.Secret.SecretName == "thesecret" {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+		It("passes the remote address to the mover", func() {
+			c := job.Spec.Template.Spec.Containers[0]
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "DESTINATION_ADDRESS", Value: "my.remote.host"}))
+		})
+		When("a user and path are specified", func() {
+			BeforeEach(func() {
+				user := "someone"
+				path := "/some/dir"
+				rs.Spec.Rsync.SSHUser = &user
+				rs.Spec.Rsync.Path = &path
+			})
+			It("passes them to the mover", func() {
+				c := job.Spec.Template.Spec.Containers[0]
+				Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "DESTINATION_USER", Value: "someone"}))
+				Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "DESTINATION_PATH", Value: "/some/dir"}))
+			})
+		})
+		It("is marked for cleanup", func() {
+			Expect(job.Labels).To(HaveKeyWithValue("scribe.backube/cleanup", string(rs.UID)))
+		})
+		When("a moverConfig is specified", func() {
+			BeforeEach(func() {
+				priorityClass := "high-priority"
+				mover.moverConfig = &scribev1alpha1.MoverConfig{
+					Resources: &v1.ResourceRequirements{
+						Limits: v1.ResourceList{
+							v1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+					NodeSelector: map[string]string{"zone": "east"},
+					Tolerations: []v1.Toleration{{
+						Key:      "dedicated",
+						Operator: v1.TolerationOpEqual,
+						Value:    "backup",
+						Effect:   v1.TaintEffectNoSchedule,
+					}},
+					PriorityClassName: &priorityClass,
+				}
+			})
+			It("is applied to the mover pod", func() {
+				podSpec := job.Spec.Template.Spec
+				Expect(podSpec.NodeSelector).To(HaveKeyWithValue("zone", "east"))
+				Expect(podSpec.Tolerations).To(HaveLen(1))
+				Expect(podSpec.Tolerations[0].Key).To(Equal("dedicated"))
+				Expect(podSpec.PriorityClassName).To(Equal("high-priority"))
+				limit := podSpec.Containers[0].Resources.Limits[v1.ResourceMemory]
+				Expect(limit.Equal(resource.MustParse("1Gi"))).To(BeTrue())
+			})
+			It("keeps the Job while it's unchanged", func() {
+				uid := job.UID
+				j, e := mover.ensureJob(ctx, sPVC, sa, "thesecret")
+				Expect(e).NotTo(HaveOccurred())
+				Expect(j).To(BeNil())
+				Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+				Expect(job.UID).To(Equal(uid))
+			})
+			It("recreates the Job when it changes", func() {
+				uid := job.UID
+				mover.moverConfig.NodeSelector = map[string]string{"zone": "west"}
+				j, e := mover.ensureJob(ctx, sPVC, sa, "thesecret")
+				Expect(e).NotTo(HaveOccurred())
+				Expect(j).To(BeNil())
+				Eventually(func() error {
+					_, e := mover.ensureJob(ctx, sPVC, sa, "thesecret")
+					if e != nil {
+						return e
+					}
+					return k8sClient.Get(ctx, nsn, job)
+				}, "10s", "1s").Should(Succeed())
+				Eventually(func() types.UID {
+					_ = k8sClient.Get(ctx, nsn, job)
+					return job.UID
+				}, "10s", "1s").ShouldNot(Equal(uid))
+				Expect(job.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("zone", "west"))
+			})
+		})
+		When("the job has failed", func() {
+			It("is deleted so it can be restarted", func() {
+				job.Status.Failed = *job.Spec.BackoffLimit
+				Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+				Eventually(func() error {
+					j, e := mover.ensureJob(ctx, sPVC, sa, "thesecret")
+					if j != nil {
+						return nil
+					}
+					return e
+				}, "10s", "1s").Should(Succeed())
+				Eventually(func() bool {
+					err := k8sClient.Get(ctx, nsn, job)
+					return err != nil || !job.DeletionTimestamp.IsZero()
+				}, "10s", "1s").Should(BeTrue())
+			})
+		})
+	})
+})
+
+var _ = Describe("Rsync as a destination", func() {
+	var ctx = context.TODO()
+	var ns *v1.Namespace
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	var rd *scribev1alpha1.ReplicationDestination
+	var mover *Mover
+	BeforeEach(func() {
+		// Create namespace for test
+		ns = &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "rsync-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		Expect(ns.Name).NotTo(BeEmpty())
+
+		capacity := resource.MustParse("2Gi")
+		rd = &scribev1alpha1.ReplicationDestination{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rd",
+				Namespace: ns.Name,
+			},
+			Spec: scribev1alpha1.ReplicationDestinationSpec{
+				Trigger: &scribev1alpha1.ReplicationDestinationTriggerSpec{},
+				Rsync: &scribev1alpha1.ReplicationDestinationRsyncSpec{
+					ReplicationDestinationVolumeOptions: scribev1alpha1.ReplicationDestinationVolumeOptions{
+						CopyMethod:  scribev1alpha1.CopyMethodSnapshot,
+						Capacity:    &capacity,
+						AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					},
+				},
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, rd)).To(Succeed())
+		// Controller sets status to non-nil
+		rd.Status = &scribev1alpha1.ReplicationDestinationStatus{}
+		// Instantiate an rsync mover for the tests
+		b := Builder{}
+		m, err := b.FromDestination(k8sClient, logger, rd)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m).NotTo(BeNil())
+		mover, _ = m.(*Mover)
+		Expect(mover).NotTo(BeNil())
+	})
+	AfterEach(func() {
+		// All resources are namespaced, so this should clean it all up
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
+	})
+
+	When("no destination volume is supplied", func() {
+		It("creates a PVC", func() {
+			pvc, err := mover.ensureDestinationPVC(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pvc).NotTo(BeNil())
+			Expect(pvc.Name).To(Equal("scribe-dest-" + rd.Name))
+		})
+	})
+
+	When("the Cleanup runs", func() {
+		It("removes the snapshot annotation from the destination PVC", func() {
+			pvc, err := mover.ensureDestinationPVC(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			pvc.Annotations = map[string]string{"scribe.backube/snapname": "snap"}
+			Expect(k8sClient.Update(ctx, pvc)).To(Succeed())
+			nsn := types.NamespacedName{Name: pvc.Name, Namespace: ns.Name}
+			Eventually(func() map[string]string {
+				_ = k8sClient.Get(ctx, nsn, pvc)
+				return pvc.Annotations
+			}, "5s", "1s").Should(HaveKey("scribe.backube/snapname"))
+
+			result, err := mover.Cleanup(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Completed).To(BeTrue())
+			Eventually(func() map[string]string {
+				_ = k8sClient.Get(ctx, nsn, pvc)
+				return pvc.Annotations
+			}, "5s", "1s").ShouldNot(HaveKey("scribe.backube/snapname"))
+		})
+	})
+
+	When("a remote address is specified", func() {
+		var sa *v1.ServiceAccount
+		BeforeEach(func() {
+			address := "my.source.host"
+			port := int32(2222)
+			user := "someone"
+			rd.Spec.Rsync.Address = &address
+			rd.Spec.Rsync.Port = &port
+			rd.Spec.Rsync.SSHUser = &user
+			sa = &v1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "thesa",
+					Namespace: ns.Name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, sa)).To(Succeed())
+		})
+		It("pulls from the source", func() {
+			pvc, err := mover.ensureDestinationPVC(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = mover.ensureJob(ctx, pvc, sa, "thesecret")
+			Expect(err).NotTo(HaveOccurred())
+			job := &batchv1.Job{}
+			nsn := types.NamespacedName{Name: "scribe-rsync-dest-" + rd.Name, Namespace: ns.Name}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, nsn, job)
+			}, "5s", "1s").Should(Succeed())
+			c := job.Spec.Template.Spec.Containers[0]
+			Expect(c.Command).To(ContainElement("/destination.sh"))
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "SOURCE_ADDRESS", Value: "my.source.host"}))
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "SOURCE_PORT", Value: "2222"}))
+			Expect(c.Env).To(ContainElement(v1.EnvVar{Name: "SOURCE_USER", Value: "someone"}))
+		})
+	})
+
+	It("generates ssh keys and publishes the source's Secret", func() {
+		Eventually(func() *string {
+			name, _ := mover.ensureSecrets(ctx)
+			return name
+		}, "30s", "1s").ShouldNot(BeNil())
+		Expect(rd.Status.Rsync.SSHKeys).NotTo(BeNil())
+		Expect(*rd.Status.Rsync.SSHKeys).To(Equal("scribe-rsync-dest-src-" + rd.Name))
+		secret := &v1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: *rd.Status.Rsync.SSHKeys, Namespace: ns.Name},
+			secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKey("source"))
+		Expect(secret.Data).To(HaveKey("destination.pub"))
+	})
+})
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"context"
+	"encoding/json"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+// SyncResultFromJob retrieves the result of a completed mover Job. Upon
+// success, mover containers write their result to /dev/termination-log as a
+// JSON-encoded SyncResult, which Kubernetes preserves as the termination
+// message of the container. It returns nil if no result is available. The
+// pods are removed along with the Job, so this must be called before cleanup.
+func SyncResultFromJob(ctx context.Context, c client.Client, job *batchv1.Job) *scribev1alpha1.SyncResult {
+	pod := latestJobPod(ctx, c, job, corev1.PodSucceeded)
+	if pod == nil {
+		return nil
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		term := cs.State.Terminated
+		if term == nil || term.Message == "" {
+			continue
+		}
+		result := &scribev1alpha1.SyncResult{}
+		if err := json.Unmarshal([]byte(term.Message), result); err != nil {
+			continue
+		}
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+// ErrInvalidSpec is wrapped by the errors that Validate returns when a CR
+// requests something that its data mover does not support.
+var ErrInvalidSpec = errors.New("invalid spec")
+
+// Validate checks the Requirements of the Mover against the Capabilities of
+// its Builder. It is intended to be called before Synchronize() so that an
+// unsupported configuration is reported before any objects are created.
+func Validate(ctx context.Context, c client.Client, owner metav1.Object,
+	m Mover, caps Capabilities) error {
+	req := m.Requirements()
+
+	if req.CopyMethod != "" && !copyMethodSupported(req.CopyMethod, caps.CopyMethods) {
+		return fmt.Errorf("%w: copyMethod %q is not supported by the %v mover -- must be one of: %v",
+			ErrInvalidSpec, req.CopyMethod, m.Name(), caps.CopyMethods)
+	}
+
+	if req.ServiceType != nil {
+		if !caps.NeedsService {
+			return fmt.Errorf("%w: the %v mover does not use a Service", ErrInvalidSpec, m.Name())
+		}
+		switch *req.ServiceType {
+		case v1.ServiceTypeClusterIP, v1.ServiceTypeNodePort, v1.ServiceTypeLoadBalancer:
+		default:
+			return fmt.Errorf("%w: serviceType %q is not supported -- must be one of: %v",
+				ErrInvalidSpec, *req.ServiceType, []v1.ServiceType{v1.ServiceTypeClusterIP,
+					v1.ServiceTypeNodePort, v1.ServiceTypeLoadBalancer})
+		}
+	}
+
+	if req.Secret != nil && len(caps.SecretKeys) > 0 {
+		secret := &v1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Name: *req.Secret, Namespace: owner.GetNamespace()},
+			secret); err != nil {
+			if kerrors.IsNotFound(err) {
+				return fmt.Errorf("%w: secret %v not found", ErrInvalidSpec, *req.Secret)
+			}
+			return err
+		}
+		for _, key := range caps.SecretKeys {
+			if _, found := secret.Data[key]; !found {
+				return fmt.Errorf("%w: secret %v is missing field: %v", ErrInvalidSpec, secret.Name, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func copyMethodSupported(cm scribev1alpha1.CopyMethodType,
+	supported []scribev1alpha1.CopyMethodType) bool {
+	for _, s := range supported {
+		if cm == s {
+			return true
+		}
+	}
+	return false
+}
@@ -80,11 +80,10 @@ var _ = Describe("ReplicationDestination [rclone]", func() {
 		// setup a minimal job
 		job = &batchv1.Job{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "scribe-rclone-src-" + rd.Name,
+				Name:      "scribe-rclone-dst-" + rd.Name,
 				Namespace: rd.Namespace,
 			},
 		}
-		RcloneContainerImage = DefaultRcloneContainerImage
 	})
 	AfterEach(func() {
 		// delete each namespace on shutdown so resources can be reclaimed
@@ -202,7 +201,7 @@ var _ = Describe("ReplicationDestination [rclone]", func() {
 						It("Job has parallelism disabled", func() {
 							job := &batchv1.Job{
 								ObjectMeta: metav1.ObjectMeta{
-									Name:      "scribe-rclone-src-" + rd.Name,
+									Name:      "scribe-rclone-dst-" + rd.Name,
 									Namespace: rd.Namespace,
 								},
 							}
@@ -343,6 +342,33 @@ var _ = Describe("ReplicationDestination [rclone]", func() {
 				reconcileCondition := inst.Status.Conditions.GetCondition(scribev1alpha1.ConditionReconciled)
 				Expect(reconcileCondition).ToNot(BeNil())
 				Expect(reconcileCondition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(reconcileCondition.Reason).To(Equal(scribev1alpha1.ReconciledReasonInvalidSpec))
+			})
+		})
+
+		Context("Secret doesn't exist", func() {
+			BeforeEach(func() {
+				missing := "missing-secret"
+				rd.Spec.Rclone = &scribev1alpha1.ReplicationDestinationRcloneSpec{
+					ReplicationDestinationVolumeOptions: scribev1alpha1.ReplicationDestinationVolumeOptions{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Capacity:    &capacity,
+					},
+					RcloneConfigSection: &configSection,
+					RcloneDestPath:      &destPath,
+					RcloneConfig:        &missing,
+				}
+			})
+			It("is reported as an invalid spec", func() {
+				inst := &scribev1alpha1.ReplicationDestination{}
+				Eventually(func() *scribev1alpha1.ReplicationDestinationStatus {
+					_ = k8sClient.Get(ctx, utils.NameFor(rd), inst)
+					return inst.Status
+				}, duration, interval).Should(Not(BeNil()))
+				reconcileCondition := inst.Status.Conditions.GetCondition(scribev1alpha1.ConditionReconciled)
+				Expect(reconcileCondition).ToNot(BeNil())
+				Expect(reconcileCondition.Status).To(Equal(corev1.ConditionFalse))
+				Expect(reconcileCondition.Reason).To(Equal(scribev1alpha1.ReconciledReasonInvalidSpec))
 			})
 		})
 
@@ -366,7 +392,7 @@ var _ = Describe("ReplicationDestination [rclone]", func() {
 				// setup a minimal job
 				job = &batchv1.Job{
 					ObjectMeta: metav1.ObjectMeta{
-						Name:      "scribe-rclone-src-" + rd.Name,
+						Name:      "scribe-rclone-dst-" + rd.Name,
 						Namespace: rd.Namespace,
 					},
 				}
@@ -481,7 +507,6 @@ var _ = Describe("ReplicationSource [rclone]", func() {
 				Namespace: namespace.Name,
 			},
 		}
-		RcloneContainerImage = DefaultRcloneContainerImage
 	})
 	AfterEach(func() {
 		// delete each namespace on shutdown so resources can be reclaimed
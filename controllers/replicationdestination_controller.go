@@ -34,28 +34,15 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 	"github.com/backube/scribe/controllers/mover"
 	"github.com/backube/scribe/controllers/utils"
 )
 
-const (
-	// DefaultRsyncContainerImage is the default container image name of the rsync data mover
-	DefaultRsyncContainerImage = "quay.io/backube/scribe-mover-rsync:latest"
-	// DefaultRcloneContainerImage is the default container image name of the rclone data mover
-	DefaultRcloneContainerImage = "quay.io/backube/scribe-mover-rclone:latest"
-)
-
 var (
-	// RsyncContainerImage is the container image name of the rsync data mover
-	RsyncContainerImage string
-	// RcloneContainerImage is the container image name of the rclone data mover
-	RcloneContainerImage string
 	// SCCName is the name of the scribe security context constraint
 	SCCName string
 )
@@ -65,6 +52,9 @@ type ReplicationDestinationReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Catalog is the list of data movers that may service the CRs. If nil,
+	// the globally registered mover.Catalog is used.
+	Catalog []mover.Builder
 }
 
 //nolint:lll
@@ -72,7 +62,9 @@ type ReplicationDestinationReconciler struct {
 //+kubebuilder:rbac:groups=scribe.backube,resources=replicationdestinations/finalizers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=scribe.backube,resources=replicationdestinations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
@@ -107,15 +99,9 @@ func (r *ReplicationDestinationReconciler) Reconcile(ctx context.Context, req ct
 		return result, err
 	}
 	result, err = reconcileDestUsingCatalog(ctx, inst, r, logger)
-	if errors.Is(err, errNoMoverFound) { // do the old stuff
-		if inst.Spec.Rsync != nil {
-			result, err = RunRsyncDestReconciler(ctx, inst, r, logger)
-		} else if inst.Spec.Rclone != nil {
-			result, err = RunRcloneDestReconciler(ctx, inst, r, logger)
-		} else {
-			// Not an internal method... we're done.
-			return ctrl.Result{}, nil
-		}
+	if errors.Is(err, errNoMoverFound) {
+		// Not an internal method... we're done.
+		return ctrl.Result{}, nil
 	}
 	// Set reconcile status condition
 	if err == nil {
@@ -127,11 +113,15 @@ func (r *ReplicationDestinationReconciler) Reconcile(ctx context.Context, req ct
 				Message: "Reconcile complete",
 			})
 	} else {
+		reason := scribev1alpha1.ReconciledReasonError
+		if errors.Is(err, mover.ErrInvalidSpec) {
+			reason = scribev1alpha1.ReconciledReasonInvalidSpec
+		}
 		inst.Status.Conditions.SetCondition(
 			status.Condition{
 				Type:    scribev1alpha1.ConditionReconciled,
 				Status:  corev1.ConditionFalse,
-				Reason:  scribev1alpha1.ReconciledReasonError,
+				Reason:  reason,
 				Message: err.Error(),
 			})
 	}
@@ -142,9 +132,9 @@ func (r *ReplicationDestinationReconciler) Reconcile(ctx context.Context, req ct
 	}
 	if !inst.Status.NextSyncTime.IsZero() {
 		// ensure we get re-reconciled no later than the next scheduled sync
-		// time
+		// time, unless the mover asked to be reconciled sooner
 		delta := time.Until(inst.Status.NextSyncTime.Time)
-		if delta > 0 {
+		if delta > 0 && (result.RequeueAfter == 0 || delta < result.RequeueAfter) {
 			result.RequeueAfter = delta
 		}
 	}
@@ -160,19 +150,33 @@ func reconcileDestUsingCatalog(
 ) (ctrl.Result, error) {
 	// Search the Mover catalog for a suitable data mover
 	var dataMover mover.Mover
-	for _, builder := range mover.Catalog {
+	var capabilities mover.Capabilities
+	catalog := mover.Catalog
+	if dr.Catalog != nil {
+		catalog = dr.Catalog
+	}
+	for _, builder := range catalog {
 		if candidate, err := builder.FromDestination(dr.Client, logger, instance); err == nil {
 			if dataMover != nil && candidate != nil {
 				// Found 2 movers claiming this CR...
 				return ctrl.Result{}, fmt.Errorf("only a single replication method can be provided")
 			}
-			dataMover = candidate
+			if candidate != nil {
+				dataMover = candidate
+				capabilities = builder.DestinationCapabilities()
+			}
 		}
 	}
 	if dataMover == nil { // No mover matched
 		return ctrl.Result{}, errNoMoverFound
 	}
 
+	// Make sure the mover can do what's being asked before it starts creating
+	// objects
+	if err := mover.Validate(ctx, dr.Client, instance, dataMover, capabilities); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	metrics := newScribeMetrics(prometheus.Labels{
 		"obj_name":      instance.Name,
 		"obj_namespace": instance.Namespace,
@@ -187,8 +191,36 @@ func reconcileDestUsingCatalog(
 
 	var result mover.Result
 	if shouldSync && !instance.Status.Conditions.IsFalseFor(scribev1alpha1.ConditionSynchronizing) {
+		// Wait out the backoff following a failure of the data mover
+		if delay := retryBackoff(instance.Spec.RetryPolicy, instance.Status.ConsecutiveFailures,
+			instance.Status.LastFailureTime); delay > 0 {
+			return mover.RetryAfter(delay).ReconcileResult(), nil
+		}
+		if instance.Status.LastSyncStartTime == nil {
+			instance.Status.LastSyncStartTime = &metav1.Time{Time: time.Now()}
+		}
 		result, err = dataMover.Synchronize(ctx)
+		if result.Progress != nil {
+			instance.Status.Progress = result.Progress
+		}
+		if result.Failure != nil {
+			logger.Info("data mover failed", "reason", result.Failure.Reason)
+			if recordSyncFailure(instance.Spec.RetryPolicy, &instance.Status.ConsecutiveFailures,
+				&instance.Status.LastFailureTime, &instance.Status.Conditions, result.Failure) {
+				if ok, err := abandonSyncDestination(instance, metrics, logger); !ok {
+					return mover.InProgress().ReconcileResult(), err
+				}
+			}
+			// The backoff is applied by the next reconcile
+			return mover.RetryAfter(0).ReconcileResult(), err
+		}
 		if result.Completed && result.Image != nil {
+			instance.Status.Progress = nil
+			// The previous image is no longer needed once it's been replaced
+			if err := utils.MarkOldSnapshotForCleanup(ctx, dr.Client, logger, instance,
+				instance.Status.LatestImage, result.Image); err != nil {
+				return mover.InProgress().ReconcileResult(), err
+			}
 			instance.Status.LatestImage = result.Image
 			instance.Status.Conditions.SetCondition(
 				status.Condition{
@@ -198,6 +230,9 @@ func reconcileDestUsingCatalog(
 					Message: "Cleaning up",
 				},
 			)
+			recordSyncSuccess(&instance.Status.ConsecutiveFailures, &instance.Status.Conditions)
+			instance.Status.LastSyncResult = result.SyncResult
+			recordSyncResult(result.SyncResult, metrics)
 			if ok, err := updateLastSyncDestination(instance, metrics, logger); !ok {
 				return mover.InProgress().ReconcileResult(), err
 			}
@@ -234,24 +269,6 @@ func (r *ReplicationDestinationReconciler) SetupWithManager(mgr ctrl.Manager) er
 		Complete(r)
 }
 
-type rsyncDestReconciler struct {
-	destinationVolumeHandler
-	scribeMetrics
-	service        *corev1.Service
-	destSecret     *corev1.Secret
-	srcSecret      *corev1.Secret
-	serviceAccount *corev1.ServiceAccount
-	job            *batchv1.Job
-}
-
-type rcloneDestReconciler struct {
-	destinationVolumeHandler
-	scribeMetrics
-	rcloneConfigSecret *corev1.Secret
-	serviceAccount     *corev1.ServiceAccount
-	job                *batchv1.Job
-}
-
 //nolint:dupl
 func updateNextSyncDestination(
 	rd *scribev1alpha1.ReplicationDestination,
@@ -279,6 +296,15 @@ func updateNextSyncDestination(
 		} else { // Never synced before, so we should ASAP
 			rd.Status.NextSyncTime = &metav1.Time{Time: time.Now()}
 		}
+
+		// After giving up on an iteration, wait for the next scheduled time
+		if syncAbandoned(rd.Status.ConsecutiveFailures, rd.Status.LastFailureTime,
+			rd.Status.LastSyncTime) {
+			next := schedule.Next(rd.Status.LastFailureTime.Time)
+			if next.After(rd.Status.NextSyncTime.Time) {
+				rd.Status.NextSyncTime = &metav1.Time{Time: next}
+			}
+		}
 	} else { // No schedule, so there's no "next"
 		rd.Status.NextSyncTime = nil
 	}
@@ -383,6 +409,14 @@ func updateLastSyncDestination(
 
 	rd.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
 
+	// If the start time was recorded, we can calculate the sync duration
+	if rd.Status.LastSyncStartTime != nil {
+		d := rd.Status.LastSyncTime.Sub(rd.Status.LastSyncStartTime.Time)
+		rd.Status.LastSyncDuration = &metav1.Duration{Duration: d}
+		metrics.SyncDurations.Observe(d.Seconds())
+		rd.Status.LastSyncStartTime = nil
+	}
+
 	// When a sync is completed we set lastManualSync
 	if rd.Spec.Trigger != nil {
 		rd.Status.LastManualSync = rd.Spec.Trigger.Manual
@@ -394,468 +428,29 @@ func updateLastSyncDestination(
 }
 
 //nolint:dupl
-func RunRsyncDestReconciler(
-	ctx context.Context,
-	instance *scribev1alpha1.ReplicationDestination,
-	dr *ReplicationDestinationReconciler,
-	logger logr.Logger,
-) (ctrl.Result, error) {
-	// Initialize state for the reconcile pass
-	r := rsyncDestReconciler{
-		destinationVolumeHandler: destinationVolumeHandler{
-			Ctx:                              ctx,
-			Instance:                         instance,
-			ReplicationDestinationReconciler: *dr,
-			Options:                          &instance.Spec.Rsync.ReplicationDestinationVolumeOptions,
-		},
-		scribeMetrics: newScribeMetrics(prometheus.Labels{
-			"obj_name":      instance.Name,
-			"obj_namespace": instance.Namespace,
-			"role":          "destination",
-			"method":        "rsync",
-		}),
-	}
-
-	l := logger.WithValues("method", "Rsync")
-
-	// Make sure there's a place to write status info
-	if r.Instance.Status.Rsync == nil {
-		r.Instance.Status.Rsync = &scribev1alpha1.ReplicationDestinationRsyncStatus{}
-	}
-
-	// wrap the scheduling functions as reconcileFuncs
-	awaitNextSync := func(l logr.Logger) (bool, error) {
-		return awaitNextSyncDestination(r.Instance, r.scribeMetrics, l)
-	}
-
-	_, err := utils.ReconcileBatch(l,
-		awaitNextSync,
-		r.EnsurePVC,
-		r.ensureService,
-		r.publishSvcAddress,
-		r.ensureSecrets,
-		r.ensureServiceAccount,
-		r.ensureJob,
-		r.PreserveImage,
-		r.cleanupJob,
-	)
-	return ctrl.Result{}, err
-}
-
-// RunRcloneDestReconciler reconciles rclone mover related objects.
-func RunRcloneDestReconciler(
-	ctx context.Context,
-	instance *scribev1alpha1.ReplicationDestination,
-	dr *ReplicationDestinationReconciler,
+func abandonSyncDestination(
+	rd *scribev1alpha1.ReplicationDestination,
+	metrics scribeMetrics,
 	logger logr.Logger,
-) (ctrl.Result, error) {
-	// Initialize state for the reconcile pass
-	r := rcloneDestReconciler{
-		destinationVolumeHandler: destinationVolumeHandler{
-			Ctx:                              ctx,
-			Instance:                         instance,
-			ReplicationDestinationReconciler: *dr,
-			Options:                          &instance.Spec.Rclone.ReplicationDestinationVolumeOptions,
+) (bool, error) {
+	logger.Info("abandoning synchronization -- too many failed attempts")
+	rd.Status.Progress = nil
+	rd.Status.LastSyncStartTime = nil
+	rd.Status.Conditions.SetCondition(
+		status.Condition{
+			Type:    scribev1alpha1.ConditionSynchronizing,
+			Status:  corev1.ConditionFalse,
+			Reason:  scribev1alpha1.SynchronizingReasonCleanup,
+			Message: "Cleaning up",
 		},
-		scribeMetrics: newScribeMetrics(prometheus.Labels{
-			"obj_name":      instance.Name,
-			"obj_namespace": instance.Namespace,
-			"role":          "destination",
-			"method":        "rclone",
-		}),
-	}
-	l := logger.WithValues("method", "Rclone")
-	// wrap the scheduling functions as reconcileFuncs
-	awaitNextSync := func(l logr.Logger) (bool, error) {
-		return awaitNextSyncDestination(r.Instance, r.scribeMetrics, l)
-	}
-	_, err := utils.ReconcileBatch(l,
-		awaitNextSync,
-		r.validateRcloneSpec,
-		r.EnsurePVC,
-		r.ensureRcloneConfig,
-		r.ensureServiceAccount,
-		r.ensureJob,
-		r.PreserveImage,
-		r.cleanupJob,
 	)
-	return ctrl.Result{}, err
-}
 
-func (r *rsyncDestReconciler) serviceSelector() map[string]string {
-	return map[string]string{
-		"app.kubernetes.io/name":      "dest-" + r.Instance.Name,
-		"app.kubernetes.io/component": "rsync-mover",
-		"app.kubernetes.io/part-of":   "scribe",
-	}
-}
-
-// ensureService maintains the Service that is used to connect to the
-// destination rsync mover.
-func (r *rsyncDestReconciler) ensureService(l logr.Logger) (bool, error) {
-	if r.Instance.Spec.Rsync.Address != nil {
-		// Connection will be outbound. Don't need a Service
-		return true, nil
-	}
-
-	r.service = &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-rsync-dest-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	svcDesc := rsyncSvcDescription{
-		Context:  r.Ctx,
-		Client:   r.Client,
-		Scheme:   r.Scheme,
-		Service:  r.service,
-		Owner:    r.Instance,
-		Type:     r.Instance.Spec.Rsync.ServiceType,
-		Selector: r.serviceSelector(),
-		Port:     r.Instance.Spec.Rsync.Port,
-	}
-	return svcDesc.Reconcile(l)
-}
-
-func (r *rsyncDestReconciler) publishSvcAddress(l logr.Logger) (bool, error) {
-	if r.service == nil { // no service, nothing to do
-		r.Instance.Status.Rsync.Address = nil
-		return true, nil
-	}
-
-	address := getServiceAddress(r.service)
-	if address == "" {
-		// We don't have an address yet, try again later
-		r.Instance.Status.Rsync.Address = nil
-		return false, nil
-	}
-	r.Instance.Status.Rsync.Address = &address
-
-	l.V(1).Info("Service addr published", "address", address)
-	return true, nil
-}
-
-//nolint:dupl
-func (r *rsyncDestReconciler) ensureSecrets(l logr.Logger) (bool, error) {
-	// If user provided keys, use those
-	if r.Instance.Spec.Rsync.SSHKeys != nil {
-		r.destSecret = &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      *r.Instance.Spec.Rsync.SSHKeys,
-				Namespace: r.Instance.Namespace,
-			},
-		}
-		fields := []string{"destination", "destination.pub", "source.pub"}
-		if err := getAndValidateSecret(r.Ctx, r.Client, l, r.destSecret, fields); err != nil {
-			l.Error(err, "SSH keys secret does not contain the proper fields")
-			return false, err
-		}
-		return true, nil
-	}
-
-	// otherwise, we need to create our own
-	keyInfo := rsyncSSHKeys{
-		Context:      r.Ctx,
-		Client:       r.Client,
-		Scheme:       r.Scheme,
-		Owner:        r.Instance,
-		NameTemplate: "scribe-rsync-dest",
-	}
-	cont, err := keyInfo.Reconcile(l)
-	if !cont || err != nil {
-		r.Instance.Status.Rsync.SSHKeys = nil
-	} else {
-		r.srcSecret = keyInfo.SrcSecret
-		r.destSecret = keyInfo.DestSecret
-		r.Instance.Status.Rsync.SSHKeys = &r.srcSecret.Name
-	}
-	return cont, err
-}
-
-func (r *rcloneDestReconciler) ensureRcloneConfig(l logr.Logger) (bool, error) {
-	// If user provided "rclone-secret", use those
-	r.rcloneConfigSecret = &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      *r.Instance.Spec.Rclone.RcloneConfig,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	fields := []string{"rclone.conf"}
-	if err := getAndValidateSecret(r.Ctx, r.Client, l, r.rcloneConfigSecret, fields); err != nil {
-		l.Error(err, "Rclone config secret does not contain the proper fields")
-		return false, err
-	}
-	l.Info("RcloneConfig reconciled")
-
-	return true, nil
-}
-
-func (r *rsyncDestReconciler) ensureServiceAccount(l logr.Logger) (bool, error) {
-	r.serviceAccount = &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-rsync-dest-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	saDesc := utils.NewSAHandler(r.Ctx, r.Client, r.Instance, r.serviceAccount)
-	return saDesc.Reconcile(l)
-}
-
-func (r *rcloneDestReconciler) ensureServiceAccount(l logr.Logger) (bool, error) {
-	r.serviceAccount = &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-src-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	saDesc := utils.NewSAHandler(r.Ctx, r.Client, r.Instance, r.serviceAccount)
-	return saDesc.Reconcile(l)
-}
-
-//nolint:funlen
-func (r *rsyncDestReconciler) ensureJob(l logr.Logger) (bool, error) {
-	jobName := types.NamespacedName{
-		Name:      "scribe-rsync-dest-" + r.Instance.Name,
-		Namespace: r.Instance.Namespace,
-	}
-	logger := l.WithValues("job", jobName)
-
-	r.job = &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      jobName.Name,
-			Namespace: jobName.Namespace,
-		},
-	}
-	op, err := ctrlutil.CreateOrUpdate(r.Ctx, r.Client, r.job, func() error {
-		if err := ctrl.SetControllerReference(r.Instance, r.job, r.Scheme); err != nil {
-			logger.Error(err, "unable to set controller reference")
-			return err
-		}
-		r.job.Spec.Template.ObjectMeta.Name = jobName.Name
-		if r.job.Spec.Template.ObjectMeta.Labels == nil {
-			r.job.Spec.Template.ObjectMeta.Labels = map[string]string{}
-		}
-		for k, v := range r.serviceSelector() {
-			r.job.Spec.Template.ObjectMeta.Labels[k] = v
-		}
-		backoffLimit := int32(2)
-		r.job.Spec.BackoffLimit = &backoffLimit
-		if r.Instance.Spec.Paused {
-			parallelism := int32(0)
-			r.job.Spec.Parallelism = &parallelism
-		} else {
-			parallelism := int32(1)
-			r.job.Spec.Parallelism = &parallelism
-		}
-		if len(r.job.Spec.Template.Spec.Containers) != 1 {
-			r.job.Spec.Template.Spec.Containers = []corev1.Container{{}}
-		}
-		r.job.Spec.Template.Spec.Containers[0].Name = "rsync"
-		r.job.Spec.Template.Spec.Containers[0].Command = []string{"/bin/bash", "-c", "/destination.sh"}
-		r.job.Spec.Template.Spec.Containers[0].Image = RsyncContainerImage
-		runAsUser := int64(0)
-		r.job.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
-			Capabilities: &corev1.Capabilities{
-				Add: []corev1.Capability{
-					"AUDIT_WRITE",
-					"SYS_CHROOT",
-				},
-			},
-			RunAsUser: &runAsUser,
-		}
-		r.job.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
-			{Name: dataVolumeName, MountPath: mountPath},
-			{Name: "keys", MountPath: "/keys"},
-		}
-		r.job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
-		r.job.Spec.Template.Spec.ServiceAccountName = r.serviceAccount.Name
-		secretMode := int32(0600)
-		r.job.Spec.Template.Spec.Volumes = []corev1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: r.PVC.Name,
-					ReadOnly:  false,
-				}},
-			},
-			{Name: "keys", VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  r.destSecret.Name,
-					DefaultMode: &secretMode,
-				}},
-			},
-		}
-		return nil
-	})
-
-	// If Job had failed, delete it so it can be recreated
-	if r.job.Status.Failed >= *r.job.Spec.BackoffLimit {
-		logger.Info("deleting job -- backoff limit reached")
-		err = r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground))
-		return false, err
-	}
-
-	if err != nil {
-		logger.Error(err, "reconcile failed")
-	} else {
-		logger.V(1).Info("Job reconciled", "operation", op)
-	}
-
-	// We only continue reconciling if the rsync job has completed
-	return r.job.Status.Succeeded == 1, nil
-}
-
-//nolint:funlen
-func (r *rcloneDestReconciler) ensureJob(l logr.Logger) (bool, error) {
-	r.job = &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-rclone-src-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	logger := l.WithValues("job", utils.NameFor(r.job))
-	op, err := ctrlutil.CreateOrUpdate(r.Ctx, r.Client, r.job, func() error {
-		if err := ctrl.SetControllerReference(r.Instance, r.job, r.Scheme); err != nil {
-			logger.Error(err, "unable to set controller reference")
-			return err
-		}
-		r.job.Spec.Template.ObjectMeta.Name = r.job.Name
-		if r.job.Spec.Template.ObjectMeta.Labels == nil {
-			r.job.Spec.Template.ObjectMeta.Labels = map[string]string{}
-		}
-		backoffLimit := int32(2)
-		r.job.Spec.BackoffLimit = &backoffLimit
-		if r.Instance.Spec.Paused {
-			parallelism := int32(0)
-			r.job.Spec.Parallelism = &parallelism
-		} else {
-			parallelism := int32(1)
-			r.job.Spec.Parallelism = &parallelism
-		}
-		if len(r.job.Spec.Template.Spec.Containers) != 1 {
-			r.job.Spec.Template.Spec.Containers = []corev1.Container{{}}
-		}
-
-		r.job.Spec.Template.Spec.Containers[0].Name = "rclone"
-		r.job.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
-			{Name: "RCLONE_CONFIG", Value: "/rclone-config/rclone.conf"},
-			{Name: "RCLONE_DEST_PATH", Value: *r.Instance.Spec.Rclone.RcloneDestPath},
-			{Name: "DIRECTION", Value: "destination"},
-			{Name: "MOUNT_PATH", Value: mountPath},
-			{Name: "RCLONE_CONFIG_SECTION", Value: *r.Instance.Spec.Rclone.RcloneConfigSection},
-		}
-		r.job.Spec.Template.Spec.Containers[0].Command = []string{"/bin/bash", "-c", "./active.sh"}
-		r.job.Spec.Template.Spec.Containers[0].Image = RcloneContainerImage
-		runAsUser := int64(0)
-		r.job.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
-			RunAsUser: &runAsUser,
-		}
-		r.job.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
-			{Name: dataVolumeName, MountPath: mountPath},
-			{Name: rcloneSecret, MountPath: "/rclone-config/"},
-		}
-		r.job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
-		r.job.Spec.Template.Spec.ServiceAccountName = r.serviceAccount.Name
-		secretMode := int32(0600)
-		r.job.Spec.Template.Spec.Volumes = []corev1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: r.PVC.Name,
-					ReadOnly:  false,
-				}},
-			},
-			{Name: rcloneSecret, VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  r.rcloneConfigSecret.Name,
-					DefaultMode: &secretMode,
-				}},
-			},
-		}
-		logger.V(1).Info("Job has PVC", "PVC", r.PVC, "DS", r.PVC.Spec.DataSource)
-		return nil
-	})
-
-	// If Job had failed, delete it so it can be recreated
-	if r.job.Status.Failed >= *r.job.Spec.BackoffLimit {
-		logger.Info("deleting job -- backoff limit reached")
-		err = r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground))
-		return false, err
-	}
-	if err != nil {
-		logger.Error(err, "reconcile failed")
+	// A manual trigger is consumed by the abandoned iteration
+	if rd.Spec.Trigger != nil {
+		rd.Status.LastManualSync = rd.Spec.Trigger.Manual
 	} else {
-		logger.V(1).Info("Job reconciled", "operation", op)
-	}
-	// We only continue reconciling if the rsync job has completed
-	return r.job.Status.Succeeded == 1, nil
-}
-
-//nolint:dupl
-func (r *rsyncDestReconciler) cleanupJob(l logr.Logger) (bool, error) {
-	logger := l.WithValues("job", r.job)
-	if cont, err := updateLastSyncDestination(r.Instance, r.scribeMetrics, logger); !cont || err != nil {
-		return cont, err
-	}
-	if r.job.Status.StartTime != nil {
-		d := r.Instance.Status.LastSyncTime.Sub(r.job.Status.StartTime.Time)
-		r.Instance.Status.LastSyncDuration = &metav1.Duration{Duration: d}
-		r.SyncDurations.Observe(d.Seconds())
-	}
-
-	// Delete the (completed) Job. The next reconcile pass will recreate it.
-	// Set propagation policy so the old pods get deleted
-	if err := r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
-		logger.Error(err, "unable to delete old job")
-		return false, err
-	}
-
-	return true, nil
-}
-
-func (r *rcloneDestReconciler) cleanupJob(l logr.Logger) (bool, error) {
-	logger := l.WithValues("job", r.job)
-	// update time/duration
-	if cont, err := updateLastSyncDestination(r.Instance, r.scribeMetrics, logger); !cont || err != nil {
-		return cont, err
-	}
-	if r.job.Status.StartTime != nil {
-		d := r.Instance.Status.LastSyncTime.Sub(r.job.Status.StartTime.Time)
-		r.Instance.Status.LastSyncDuration = &metav1.Duration{Duration: d}
-		r.SyncDurations.Observe(d.Seconds())
-	}
-	// remove job
-	if r.job.Status.Succeeded >= 1 {
-		logger.Info("Job succeeded", "Job", r.job.Spec)
-
-		if err := r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
-			logger.Error(err, "unable to delete job")
-			return false, err
-		}
-		logger.Info("Job deleted", "Job", r.job.Spec)
-	}
-	return true, nil
-}
-
-func (r *rcloneDestReconciler) validateRcloneSpec(l logr.Logger) (bool, error) {
-	l.V(1).Info("Initiate RcloneSpec validation")
-	rclone := r.destinationVolumeHandler.Instance.Spec.Rclone
-	if len(*rclone.RcloneConfig) == 0 {
-		l.V(1).Info("couldnt validate rcloneconfig")
-		err := errors.New("Unable to get Rclone config secret name")
-		l.V(1).Info("Unable to get Rclone config secret name")
-		return false, err
-	}
-	if len(*r.Instance.Spec.Rclone.RcloneConfigSection) == 0 {
-		err := errors.New("Unable to get Rclone config section name")
-		l.V(1).Info("Unable to get Rclone config section name")
-
-		return false, err
+		rd.Status.LastManualSync = ""
 	}
-	if len(*r.Instance.Spec.Rclone.RcloneDestPath) == 0 {
-		err := errors.New("Unable to get Rclone destination name")
-		l.V(1).Info("Unable to get Rclone destination name")
 
-		return false, err
-	}
-	l.V(1).Info("Rclone config validation complete.")
-	return true, nil
+	return updateNextSyncDestination(rd, metrics, logger)
 }
@@ -149,7 +149,6 @@ var _ = Describe("ReplicationDestination", func() {
 				Namespace: namespace.Name,
 			},
 		}
-		RsyncContainerImage = DefaultRsyncContainerImage
 	})
 	AfterEach(func() {
 		// All resources are namespaced, so this should clean it all up
@@ -242,6 +241,36 @@ var _ = Describe("ReplicationDestination", func() {
 		})
 	})
 
+	Context("when the mover doesn't support the requested copyMethod", func() {
+		capacity := resource.MustParse("2Gi")
+		BeforeEach(func() {
+			rd.Spec.Rsync = &scribev1alpha1.ReplicationDestinationRsyncSpec{
+				ReplicationDestinationVolumeOptions: scribev1alpha1.ReplicationDestinationVolumeOptions{
+					CopyMethod:  scribev1alpha1.CopyMethodClone,
+					Capacity:    &capacity,
+					AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				},
+			}
+		})
+		It("is rejected before any objects are created", func() {
+			var cond *status.Condition
+			Eventually(func() *status.Condition {
+				_ = k8sClient.Get(ctx, utils.NameFor(rd), rd)
+				if rd.Status == nil {
+					return nil
+				}
+				cond = rd.Status.Conditions.GetCondition(scribev1alpha1.ConditionReconciled)
+				return cond
+			}, maxWait, interval).Should(Not(BeNil()))
+			Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(scribev1alpha1.ReconciledReasonInvalidSpec))
+			Expect(cond.Message).To(ContainSubstring("Clone"))
+			pvcs := &v1.PersistentVolumeClaimList{}
+			Expect(k8sClient.List(ctx, pvcs, client.InNamespace(rd.Namespace))).To(Succeed())
+			Expect(pvcs.Items).To(BeEmpty())
+		})
+	})
+
 	Context("when capacity and accessModes are specified", func() {
 		capacity := resource.MustParse("2Gi")
 		accessModes := []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
@@ -21,7 +21,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -37,16 +36,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 	"github.com/backube/scribe/controllers/mover"
-	"github.com/backube/scribe/controllers/utils"
-)
-
-const (
-	// mountPath is the source and destination data directory location
-	mountPath = "/data"
 )
 
 // ReplicationSourceReconciler reconciles a ReplicationSource object
@@ -54,6 +46,9 @@ type ReplicationSourceReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Catalog is the list of data movers that may service the CRs. If nil,
+	// the globally registered mover.Catalog is used.
+	Catalog []mover.Builder
 }
 
 //nolint:lll
@@ -62,7 +57,9 @@ type ReplicationSourceReconciler struct {
 //+kubebuilder:rbac:groups=scribe.backube,resources=replicationsources/finalizers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=scribe.backube,resources=replicationsources/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
@@ -96,14 +93,9 @@ func (r *ReplicationSourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return result, err
 	}
 	result, err = reconcileSrcUsingCatalog(ctx, inst, r, logger)
-	if errors.Is(err, errNoMoverFound) { // do the old stuff
-		if inst.Spec.Rsync != nil {
-			result, err = RunRsyncSrcReconciler(ctx, inst, r, logger)
-		} else if inst.Spec.Rclone != nil {
-			result, err = RunRcloneSrcReconciler(ctx, inst, r, logger)
-		} else {
-			return ctrl.Result{}, nil
-		}
+	if errors.Is(err, errNoMoverFound) {
+		// Not an internal method... we're done.
+		return ctrl.Result{}, nil
 	}
 
 	// Set reconcile status condition
@@ -116,11 +108,15 @@ func (r *ReplicationSourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 				Message: "Reconcile complete",
 			})
 	} else {
+		reason := scribev1alpha1.ReconciledReasonError
+		if errors.Is(err, mover.ErrInvalidSpec) {
+			reason = scribev1alpha1.ReconciledReasonInvalidSpec
+		}
 		inst.Status.Conditions.SetCondition(
 			status.Condition{
 				Type:    scribev1alpha1.ConditionReconciled,
 				Status:  corev1.ConditionFalse,
-				Reason:  scribev1alpha1.ReconciledReasonError,
+				Reason:  reason,
 				Message: err.Error(),
 			})
 	}
@@ -132,9 +128,9 @@ func (r *ReplicationSourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 	if !inst.Status.NextSyncTime.IsZero() {
 		// ensure we get re-reconciled no later than the next scheduled sync
-		// time
+		// time, unless the mover asked to be reconciled sooner
 		delta := time.Until(inst.Status.NextSyncTime.Time)
-		if delta > 0 {
+		if delta > 0 && (result.RequeueAfter == 0 || delta < result.RequeueAfter) {
 			result.RequeueAfter = delta
 		}
 	}
@@ -152,19 +148,33 @@ func reconcileSrcUsingCatalog(
 ) (ctrl.Result, error) {
 	// Search the Mover catalog for a suitable data mover
 	var dataMover mover.Mover
-	for _, builder := range mover.Catalog {
+	var capabilities mover.Capabilities
+	catalog := mover.Catalog
+	if sr.Catalog != nil {
+		catalog = sr.Catalog
+	}
+	for _, builder := range catalog {
 		if candidate, err := builder.FromSource(sr.Client, logger, instance); err == nil {
 			if dataMover != nil && candidate != nil {
 				// Found 2 movers claiming this CR...
 				return ctrl.Result{}, fmt.Errorf("only a single replication method can be provided")
 			}
-			dataMover = candidate
+			if candidate != nil {
+				dataMover = candidate
+				capabilities = builder.SourceCapabilities()
+			}
 		}
 	}
 	if dataMover == nil { // No mover matched
 		return ctrl.Result{}, errNoMoverFound
 	}
 
+	// Make sure the mover can do what's being asked before it starts creating
+	// objects
+	if err := mover.Validate(ctx, sr.Client, instance, dataMover, capabilities); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	metrics := newScribeMetrics(prometheus.Labels{
 		"obj_name":      instance.Name,
 		"obj_namespace": instance.Namespace,
@@ -178,8 +188,31 @@ func reconcileSrcUsingCatalog(
 
 	var mResult mover.Result
 	if shouldSync && !instance.Status.Conditions.IsFalseFor(scribev1alpha1.ConditionSynchronizing) {
+		// Wait out the backoff following a failure of the data mover
+		if delay := retryBackoff(instance.Spec.RetryPolicy, instance.Status.ConsecutiveFailures,
+			instance.Status.LastFailureTime); delay > 0 {
+			return mover.RetryAfter(delay).ReconcileResult(), nil
+		}
+		if instance.Status.LastSyncStartTime == nil {
+			instance.Status.LastSyncStartTime = &metav1.Time{Time: time.Now()}
+		}
 		mResult, err = dataMover.Synchronize(ctx)
+		if mResult.Progress != nil {
+			instance.Status.Progress = mResult.Progress
+		}
+		if mResult.Failure != nil {
+			logger.Info("data mover failed", "reason", mResult.Failure.Reason)
+			if recordSyncFailure(instance.Spec.RetryPolicy, &instance.Status.ConsecutiveFailures,
+				&instance.Status.LastFailureTime, &instance.Status.Conditions, mResult.Failure) {
+				if ok, err := abandonSyncSource(instance, metrics, logger); !ok {
+					return mover.InProgress().ReconcileResult(), err
+				}
+			}
+			// The backoff is applied by the next reconcile
+			return mover.RetryAfter(0).ReconcileResult(), err
+		}
 		if mResult.Completed {
+			instance.Status.Progress = nil
 			instance.Status.Conditions.SetCondition(
 				status.Condition{
 					Type:    scribev1alpha1.ConditionSynchronizing,
@@ -188,6 +221,9 @@ func reconcileSrcUsingCatalog(
 					Message: "Cleaning up",
 				},
 			)
+			recordSyncSuccess(&instance.Status.ConsecutiveFailures, &instance.Status.Conditions)
+			instance.Status.LastSyncResult = mResult.SyncResult
+			recordSyncResult(mResult.SyncResult, metrics)
 			if ok, err := updateLastSyncSource(instance, metrics, logger); !ok {
 				return mover.InProgress().ReconcileResult(), err
 			}
@@ -260,6 +296,15 @@ func updateNextSyncSource(
 		} else { // Never synced before, so we should ASAP
 			rs.Status.NextSyncTime = &metav1.Time{Time: time.Now()}
 		}
+
+		// After giving up on an iteration, wait for the next scheduled time
+		if syncAbandoned(rs.Status.ConsecutiveFailures, rs.Status.LastFailureTime,
+			rs.Status.LastSyncTime) {
+			next := schedule.Next(rs.Status.LastFailureTime.Time)
+			if next.After(rs.Status.NextSyncTime.Time) {
+				rs.Status.NextSyncTime = &metav1.Time{Time: next}
+			}
+		}
 	} else { // No schedule, so there's no "next"
 		rs.Status.NextSyncTime = nil
 	}
@@ -364,6 +409,14 @@ func updateLastSyncSource(
 
 	rs.Status.LastSyncTime = &metav1.Time{Time: time.Now()}
 
+	// If the start time was recorded, we can calculate the sync duration
+	if rs.Status.LastSyncStartTime != nil {
+		d := rs.Status.LastSyncTime.Sub(rs.Status.LastSyncStartTime.Time)
+		rs.Status.LastSyncDuration = &metav1.Duration{Duration: d}
+		metrics.SyncDurations.Observe(d.Seconds())
+		rs.Status.LastSyncStartTime = nil
+	}
+
 	// When a sync is completed we set lastManualSync
 	if rs.Spec.Trigger != nil {
 		rs.Status.LastManualSync = rs.Spec.Trigger.Manual
@@ -374,487 +427,30 @@ func updateLastSyncSource(
 	return updateNextSyncSource(rs, metrics, logger)
 }
 
-type rsyncSrcReconciler struct {
-	sourceVolumeHandler
-	scribeMetrics
-	service        *corev1.Service
-	destSecret     *corev1.Secret
-	srcSecret      *corev1.Secret
-	serviceAccount *corev1.ServiceAccount
-	job            *batchv1.Job
-}
-
-type rcloneSrcReconciler struct {
-	sourceVolumeHandler
-	scribeMetrics
-	rcloneConfigSecret *corev1.Secret
-	serviceAccount     *corev1.ServiceAccount
-	job                *batchv1.Job
-}
-
 //nolint:dupl
-func RunRsyncSrcReconciler(
-	ctx context.Context,
-	instance *scribev1alpha1.ReplicationSource,
-	sr *ReplicationSourceReconciler,
-	logger logr.Logger,
-) (ctrl.Result, error) {
-	r := rsyncSrcReconciler{
-		sourceVolumeHandler: sourceVolumeHandler{
-			Ctx:                         ctx,
-			Instance:                    instance,
-			ReplicationSourceReconciler: *sr,
-			Options:                     &instance.Spec.Rsync.ReplicationSourceVolumeOptions,
-		},
-		scribeMetrics: newScribeMetrics(prometheus.Labels{
-			"obj_name":      instance.Name,
-			"obj_namespace": instance.Namespace,
-			"role":          "source",
-			"method":        "rsync",
-		}),
-	}
-
-	l := logger.WithValues("method", "Rsync")
-
-	// Make sure there's a place to write status info
-	if r.Instance.Status.Rsync == nil {
-		r.Instance.Status.Rsync = &scribev1alpha1.ReplicationSourceRsyncStatus{}
-	}
-
-	// wrap the scheduling functions as reconcileFuncs
-	awaitNextSync := func(l logr.Logger) (bool, error) {
-		return awaitNextSyncSource(r.Instance, r.scribeMetrics, l)
-	}
-
-	_, err := utils.ReconcileBatch(l,
-		awaitNextSync,
-		r.EnsurePVC,
-		r.ensureService,
-		r.publishSvcAddress,
-		r.ensureKeys,
-		r.ensureServiceAccount,
-		r.ensureJob,
-		r.cleanupJob,
-		r.CleanupPVC,
-	)
-	return ctrl.Result{}, err
-}
-
-// RunRcloneSrcReconciler is invoked when ReplicationSource.Spec.Rclone != nil
-func RunRcloneSrcReconciler(
-	ctx context.Context,
-	instance *scribev1alpha1.ReplicationSource,
-	sr *ReplicationSourceReconciler,
+func abandonSyncSource(
+	rs *scribev1alpha1.ReplicationSource,
+	metrics scribeMetrics,
 	logger logr.Logger,
-) (ctrl.Result, error) {
-	r := rcloneSrcReconciler{
-		sourceVolumeHandler: sourceVolumeHandler{
-			Ctx:                         ctx,
-			Instance:                    instance,
-			ReplicationSourceReconciler: *sr,
-			Options:                     &instance.Spec.Rclone.ReplicationSourceVolumeOptions,
+) (bool, error) {
+	logger.Info("abandoning synchronization -- too many failed attempts")
+	rs.Status.Progress = nil
+	rs.Status.LastSyncStartTime = nil
+	rs.Status.Conditions.SetCondition(
+		status.Condition{
+			Type:    scribev1alpha1.ConditionSynchronizing,
+			Status:  corev1.ConditionFalse,
+			Reason:  scribev1alpha1.SynchronizingReasonCleanup,
+			Message: "Cleaning up",
 		},
-		scribeMetrics: newScribeMetrics(prometheus.Labels{
-			"obj_name":      instance.Name,
-			"obj_namespace": instance.Namespace,
-			"role":          "source",
-			"method":        "rclone",
-		}),
-	}
-
-	l := logger.WithValues("method", "Rclone")
-
-	// wrap the scheduling functions as reconcileFuncs
-	awaitNextSync := func(l logr.Logger) (bool, error) {
-		return awaitNextSyncSource(r.Instance, r.scribeMetrics, l)
-	}
-
-	_, err := utils.ReconcileBatch(l,
-		awaitNextSync,
-		r.validateRcloneSpec,
-		r.EnsurePVC,
-		r.ensureServiceAccount,
-		r.ensureRcloneConfig,
-		r.ensureJob,
-		r.cleanupJob,
-		r.CleanupPVC,
 	)
-	return ctrl.Result{}, err
-}
 
-//nolint:funlen
-func (r *rcloneSrcReconciler) ensureJob(l logr.Logger) (bool, error) {
-	r.job = &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-rclone-src-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	logger := l.WithValues("job", utils.NameFor(r.job))
-	op, err := ctrlutil.CreateOrUpdate(r.Ctx, r.Client, r.job, func() error {
-		if err := ctrl.SetControllerReference(r.Instance, r.job, r.Scheme); err != nil {
-			logger.Error(err, "unable to set controller reference")
-			return err
-		}
-		r.job.Spec.Template.ObjectMeta.Name = r.job.Name
-		if r.job.Spec.Template.ObjectMeta.Labels == nil {
-			r.job.Spec.Template.ObjectMeta.Labels = map[string]string{}
-		}
-		backoffLimit := int32(2)
-		r.job.Spec.BackoffLimit = &backoffLimit
-		if r.Instance.Spec.Paused {
-			parallelism := int32(0)
-			r.job.Spec.Parallelism = &parallelism
-		} else {
-			parallelism := int32(1)
-			r.job.Spec.Parallelism = &parallelism
-		}
-		if len(r.job.Spec.Template.Spec.Containers) != 1 {
-			r.job.Spec.Template.Spec.Containers = []corev1.Container{{}}
-		}
-
-		r.job.Spec.Template.Spec.Containers[0].Name = "rclone"
-		r.job.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
-			{Name: "RCLONE_CONFIG", Value: "/rclone-config/rclone.conf"},
-			{Name: "RCLONE_DEST_PATH", Value: *r.Instance.Spec.Rclone.RcloneDestPath},
-			{Name: "DIRECTION", Value: "source"},
-			{Name: "MOUNT_PATH", Value: mountPath},
-			{Name: "RCLONE_CONFIG_SECTION", Value: *r.Instance.Spec.Rclone.RcloneConfigSection},
-		}
-		r.job.Spec.Template.Spec.Containers[0].Command = []string{"/bin/bash", "-c", "./active.sh"}
-		r.job.Spec.Template.Spec.Containers[0].Image = RcloneContainerImage
-		runAsUser := int64(0)
-		r.job.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
-			RunAsUser: &runAsUser,
-		}
-		r.job.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
-			{Name: dataVolumeName, MountPath: mountPath},
-			{Name: rcloneSecret, MountPath: "/rclone-config/"},
-		}
-		r.job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
-		r.job.Spec.Template.Spec.ServiceAccountName = r.serviceAccount.Name
-		secretMode := int32(0600)
-		r.job.Spec.Template.Spec.Volumes = []corev1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: r.PVC.Name,
-				}},
-			},
-			{Name: rcloneSecret, VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  r.rcloneConfigSecret.Name,
-					DefaultMode: &secretMode,
-				}},
-			},
-		}
-		logger.V(1).Info("Job has PVC", "PVC", r.PVC, "DS", r.PVC.Spec.DataSource)
-		return nil
-	})
-	// If Job had failed, delete it so it can be recreated
-	if r.job.Status.Failed >= *r.job.Spec.BackoffLimit {
-		logger.Info("deleting job -- backoff limit reached")
-		err = r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground))
-		return false, err
-	}
-	if err != nil {
-		logger.Error(err, "reconcile failed")
-	} else {
-		logger.V(1).Info("Job reconciled", "operation", op)
-	}
-	// We only continue reconciling if the rclone job has completed
-	return r.job.Status.Succeeded == 1, nil
-}
-
-func (r *rsyncSrcReconciler) serviceSelector() map[string]string {
-	return map[string]string{
-		"app.kubernetes.io/name":      "src-" + r.Instance.Name,
-		"app.kubernetes.io/component": "rsync-mover",
-		"app.kubernetes.io/part-of":   "scribe",
-	}
-}
-
-// ensureService maintains the Service that is used to connect to the
-// source rsync mover.
-func (r *rsyncSrcReconciler) ensureService(l logr.Logger) (bool, error) {
-	if r.Instance.Spec.Rsync.Address != nil {
-		// Connection will be outbound. Don't need a Service
-		return true, nil
-	}
-
-	r.service = &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-rsync-src-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	svcDesc := rsyncSvcDescription{
-		Context:  r.Ctx,
-		Client:   r.Client,
-		Scheme:   r.Scheme,
-		Service:  r.service,
-		Owner:    r.Instance,
-		Type:     r.Instance.Spec.Rsync.ServiceType,
-		Selector: r.serviceSelector(),
-		Port:     r.Instance.Spec.Rsync.Port,
-	}
-	return svcDesc.Reconcile(l)
-}
-
-func (r *rsyncSrcReconciler) publishSvcAddress(l logr.Logger) (bool, error) {
-	if r.service == nil { // no service, nothing to do
-		r.Instance.Status.Rsync.Address = nil
-		return true, nil
-	}
-
-	address := getServiceAddress(r.service)
-	if address == "" {
-		// We don't have an address yet, try again later
-		r.Instance.Status.Rsync.Address = nil
-		return false, nil
-	}
-	r.Instance.Status.Rsync.Address = &address
-
-	l.V(1).Info("Service addr published", "address", address)
-	return true, nil
-}
-
-//nolint:dupl
-func (r *rsyncSrcReconciler) ensureKeys(l logr.Logger) (bool, error) {
-	// If user provided keys, use those
-	if r.Instance.Spec.Rsync.SSHKeys != nil {
-		r.srcSecret = &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      *r.Instance.Spec.Rsync.SSHKeys,
-				Namespace: r.Instance.Namespace,
-			},
-		}
-		fields := []string{"source", "source.pub", "destination.pub"}
-		if err := getAndValidateSecret(r.Ctx, r.Client, l, r.srcSecret, fields); err != nil {
-			l.Error(err, "SSH keys secret does not contain the proper fields")
-			return false, err
-		}
-		return true, nil
-	}
-
-	// otherwise, we need to create our own
-	keyInfo := rsyncSSHKeys{
-		Context:      r.Ctx,
-		Client:       r.Client,
-		Scheme:       r.Scheme,
-		Owner:        r.Instance,
-		NameTemplate: "scribe-rsync-src",
-	}
-	cont, err := keyInfo.Reconcile(l)
-	if !cont || err != nil {
-		r.Instance.Status.Rsync.SSHKeys = nil
-	} else {
-		r.srcSecret = keyInfo.SrcSecret
-		r.destSecret = keyInfo.DestSecret
-		r.Instance.Status.Rsync.SSHKeys = &r.destSecret.Name
-	}
-	return cont, err
-}
-
-func (r *rcloneSrcReconciler) ensureRcloneConfig(l logr.Logger) (bool, error) {
-	// If user provided "rclone-secret", use those
-
-	r.rcloneConfigSecret = &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      *r.Instance.Spec.Rclone.RcloneConfig,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	fields := []string{"rclone.conf"}
-	if err := getAndValidateSecret(r.Ctx, r.Client, l, r.rcloneConfigSecret, fields); err != nil {
-		l.Error(err, "Rclone config secret does not contain the proper fields")
-		return false, err
-	}
-	return true, nil
-}
-
-func (r *rsyncSrcReconciler) ensureServiceAccount(l logr.Logger) (bool, error) {
-	r.serviceAccount = &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-rsync-src-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	saDesc := utils.NewSAHandler(r.Ctx, r.Client, r.Instance, r.serviceAccount)
-	return saDesc.Reconcile(l)
-}
-
-func (r *rcloneSrcReconciler) ensureServiceAccount(l logr.Logger) (bool, error) {
-	r.serviceAccount = &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-src-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	saDesc := utils.NewSAHandler(r.Ctx, r.Client, r.Instance, r.serviceAccount)
-	return saDesc.Reconcile(l)
-}
-
-//nolint:funlen
-func (r *rsyncSrcReconciler) ensureJob(l logr.Logger) (bool, error) {
-	r.job = &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "scribe-rsync-src-" + r.Instance.Name,
-			Namespace: r.Instance.Namespace,
-		},
-	}
-	logger := l.WithValues("job", utils.NameFor(r.job))
-
-	op, err := ctrlutil.CreateOrUpdate(r.Ctx, r.Client, r.job, func() error {
-		if err := ctrl.SetControllerReference(r.Instance, r.job, r.Scheme); err != nil {
-			logger.Error(err, "unable to set controller reference")
-			return err
-		}
-		r.job.Spec.Template.ObjectMeta.Name = r.job.Name
-		if r.job.Spec.Template.ObjectMeta.Labels == nil {
-			r.job.Spec.Template.ObjectMeta.Labels = map[string]string{}
-		}
-		for k, v := range r.serviceSelector() {
-			r.job.Spec.Template.ObjectMeta.Labels[k] = v
-		}
-		backoffLimit := int32(2)
-		r.job.Spec.BackoffLimit = &backoffLimit
-		if r.Instance.Spec.Paused {
-			parallelism := int32(0)
-			r.job.Spec.Parallelism = &parallelism
-		} else {
-			parallelism := int32(1)
-			r.job.Spec.Parallelism = &parallelism
-		}
-		if len(r.job.Spec.Template.Spec.Containers) != 1 {
-			r.job.Spec.Template.Spec.Containers = []corev1.Container{{}}
-		}
-		r.job.Spec.Template.Spec.Containers[0].Name = "rsync"
-		if r.Instance.Spec.Rsync.Port != nil && r.Instance.Spec.Rsync.Address != nil {
-			connectPort := strconv.Itoa(int(*r.Instance.Spec.Rsync.Port))
-			r.job.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
-				{Name: "DESTINATION_ADDRESS", Value: *r.Instance.Spec.Rsync.Address},
-				{Name: "DESTINATION_PORT", Value: connectPort},
-			}
-		} else if r.Instance.Spec.Rsync.Port == nil && r.Instance.Spec.Rsync.Address != nil {
-			r.job.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
-				{Name: "DESTINATION_ADDRESS", Value: *r.Instance.Spec.Rsync.Address},
-			}
-		} else if r.Instance.Spec.Rsync.Address == nil {
-			r.job.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{}
-		}
-		r.job.Spec.Template.Spec.Containers[0].Command = []string{"/bin/bash", "-c", "/source.sh"}
-		r.job.Spec.Template.Spec.Containers[0].Image = RsyncContainerImage
-		runAsUser := int64(0)
-		r.job.Spec.Template.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
-			Capabilities: &corev1.Capabilities{
-				Add: []corev1.Capability{
-					"AUDIT_WRITE",
-					"SYS_CHROOT",
-				},
-			},
-			RunAsUser: &runAsUser,
-		}
-		r.job.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
-			{Name: dataVolumeName, MountPath: mountPath},
-			{Name: "keys", MountPath: "/keys"},
-		}
-		r.job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
-		r.job.Spec.Template.Spec.ServiceAccountName = r.serviceAccount.Name
-		secretMode := int32(0600)
-		r.job.Spec.Template.Spec.Volumes = []corev1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: r.PVC.Name,
-				}},
-			},
-			{Name: "keys", VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  r.srcSecret.Name,
-					DefaultMode: &secretMode,
-				}},
-			},
-		}
-		logger.V(1).Info("Job has PVC", "PVC", r.PVC, "DS", r.PVC.Spec.DataSource)
-		return nil
-	})
-
-	// If Job had failed, delete it so it can be recreated
-	if r.job.Status.Failed >= *r.job.Spec.BackoffLimit {
-		logger.Info("deleting job -- backoff limit reached")
-		err = r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground))
-		return false, err
-	}
-
-	if err != nil {
-		logger.Error(err, "reconcile failed")
+	// A manual trigger is consumed by the abandoned iteration
+	if rs.Spec.Trigger != nil {
+		rs.Status.LastManualSync = rs.Spec.Trigger.Manual
 	} else {
-		logger.V(1).Info("Job reconciled", "operation", op)
-	}
-
-	// We only continue reconciling if the rsync job has completed
-	return r.job.Status.Succeeded == 1, nil
-}
-
-//nolint:dupl
-func (r *rsyncSrcReconciler) cleanupJob(l logr.Logger) (bool, error) {
-	logger := l.WithValues("job", r.job)
-	// update time/duration
-	if cont, err := updateLastSyncSource(r.Instance, r.scribeMetrics, logger); !cont || err != nil {
-		return cont, err
-	}
-	if r.job.Status.StartTime != nil {
-		d := r.Instance.Status.LastSyncTime.Sub(r.job.Status.StartTime.Time)
-		r.Instance.Status.LastSyncDuration = &metav1.Duration{Duration: d}
-		r.SyncDurations.Observe(d.Seconds())
-	}
-	// remove job
-	if err := r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
-		logger.Error(err, "unable to delete job")
-		return false, err
-	}
-	return true, nil
-}
-
-//nolint:dupl
-func (r *rcloneSrcReconciler) cleanupJob(l logr.Logger) (bool, error) {
-	logger := l.WithValues("job", r.job)
-	// update time/duration
-	if cont, err := updateLastSyncSource(r.Instance, r.scribeMetrics, logger); !cont || err != nil {
-		return cont, err
-	}
-	if r.job.Status.StartTime != nil {
-		d := r.Instance.Status.LastSyncTime.Sub(r.job.Status.StartTime.Time)
-		r.Instance.Status.LastSyncDuration = &metav1.Duration{Duration: d}
-		r.SyncDurations.Observe(d.Seconds())
-	}
-	// remove job
-	if r.job.Status.Succeeded >= 1 {
-		if err := r.Client.Delete(r.Ctx, r.job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
-			logger.Error(err, "unable to delete job")
-			return false, err
-		}
-		logger.Info("Job deleted", "Job name: ", r.job.Spec.Template.ObjectMeta.Name)
-	}
-	return true, nil
-}
-
-func (r *rcloneSrcReconciler) validateRcloneSpec(l logr.Logger) (bool, error) {
-	if len(*r.Instance.Spec.Rclone.RcloneConfig) == 0 {
-		err := errors.New("Unable to get Rclone config secret name")
-		l.V(1).Info("Unable to get Rclone config secret name")
-		return false, err
-	}
-	if len(*r.Instance.Spec.Rclone.RcloneConfigSection) == 0 {
-		err := errors.New("Unable to get Rclone config section name")
-		l.V(1).Info("Unable to get Rclone config section name")
-
-		return false, err
+		rs.Status.LastManualSync = ""
 	}
-	if len(*r.Instance.Spec.Rclone.RcloneDestPath) == 0 {
-		err := errors.New("Unable to get Rclone destination name")
-		l.V(1).Info("Unable to get Rclone destination name")
 
-		return false, err
-	}
-	return true, nil
+	return updateNextSyncSource(rs, metrics, logger)
 }
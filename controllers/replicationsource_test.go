@@ -8,6 +8,7 @@ import (
 	"time"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
 	"github.com/backube/scribe/controllers/utils"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
 	. "github.com/onsi/ginkgo"
@@ -16,6 +17,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -96,6 +98,57 @@ var _ = Describe("Source trigger", func() {
 		})
 	})
 
+	Context("When a scheduled iteration has failed", func() {
+		var schedule = "0 */2 * * *"
+		metrics := newScribeMetrics(prometheus.Labels{"obj_name": "a", "obj_namespace": "b", "role": "c", "method": "d"})
+		BeforeEach(func() {
+			maxAttempts := int32(2)
+			rs.Spec.Trigger = &scribev1alpha1.ReplicationSourceTriggerSpec{
+				Schedule: &schedule,
+			}
+			rs.Spec.RetryPolicy = &scribev1alpha1.RetryPolicy{
+				MaxAttempts: &maxAttempts,
+			}
+			lastSync := metav1.Time{Time: time.Now().Add(-5 * time.Hour)}
+			rs.Status.LastSyncTime = &lastSync
+			lastFailure := metav1.Time{Time: time.Now().Add(-1 * time.Minute)}
+			rs.Status.LastFailureTime = &lastFailure
+		})
+		It("if attempts remain, keep syncing", func() {
+			rs.Status.ConsecutiveFailures = 1
+			b, e := awaitNextSyncSource(rs, metrics, logger)
+			Expect(b).To(BeTrue())
+			Expect(e).To(BeNil())
+		})
+		It("if the iteration was abandoned, wait", func() {
+			rs.Status.ConsecutiveFailures = 0
+			b, e := awaitNextSyncSource(rs, metrics, logger)
+			Expect(b).To(BeFalse())
+			Expect(e).To(BeNil())
+			Expect(rs.Status.NextSyncTime.Time).To(BeTemporally(">", rs.Status.LastFailureTime.Time))
+		})
+		It("abandons the iteration once maxAttempts is reached, even if it was lowered", func() {
+			failure := &mover.Failure{Reason: "Error", Message: "boom"}
+			failures := int32(1)
+			Expect(recordSyncFailure(rs.Spec.RetryPolicy, &failures, &rs.Status.LastFailureTime,
+				&rs.Status.Conditions, failure)).To(BeTrue())
+			Expect(failures).To(BeZero())
+			Expect(syncAbandoned(failures, rs.Status.LastFailureTime, rs.Status.LastSyncTime)).To(BeTrue())
+
+			failures = int32(5)
+			Expect(recordSyncFailure(rs.Spec.RetryPolicy, &failures, &rs.Status.LastFailureTime,
+				&rs.Status.Conditions, failure)).To(BeTrue())
+			Expect(failures).To(BeZero())
+			cond := rs.Status.Conditions.GetCondition(scribev1alpha1.ConditionSyncFailed)
+			Expect(cond.Message).To(Equal("giving up after 6 attempts: boom"))
+		})
+		It("isn't abandoned once a synchronization succeeds", func() {
+			lastSync := metav1.Time{Time: rs.Status.LastFailureTime.Add(time.Minute)}
+			Expect(syncAbandoned(0, rs.Status.LastFailureTime, &lastSync)).To(BeFalse())
+			Expect(syncAbandoned(1, rs.Status.LastFailureTime, rs.Status.LastSyncTime)).To(BeFalse())
+		})
+	})
+
 	Context("When the trigger is empty", func() {
 		metrics := newScribeMetrics(prometheus.Labels{"obj_name": "a", "obj_namespace": "b", "role": "c", "method": "d"})
 		It("if never synced, sync now", func() {
@@ -167,7 +220,6 @@ var _ = Describe("ReplicationSource", func() {
 				SourcePVC: srcPVC.Name,
 			},
 		}
-		RsyncContainerImage = DefaultRsyncContainerImage
 	})
 	AfterEach(func() {
 		// All resources are namespaced, so this should clean it all up
@@ -444,6 +496,56 @@ var _ = Describe("ReplicationSource", func() {
 		})
 	})
 
+	Context("when the mover Job fails", func() {
+		var job *batchv1.Job
+		BeforeEach(func() {
+			rs.Spec.Rsync = &scribev1alpha1.ReplicationSourceRsyncSpec{
+				ReplicationSourceVolumeOptions: scribev1alpha1.ReplicationSourceVolumeOptions{
+					CopyMethod: scribev1alpha1.CopyMethodClone,
+				},
+			}
+		})
+		JustBeforeEach(func() {
+			job = &batchv1.Job{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rsync-src-" + rs.Name, Namespace: rs.Namespace}, job)
+			}, maxWait, interval).Should(Succeed())
+			job.Status.Failed = *job.Spec.BackoffLimit
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+		})
+		It("the failure is recorded in the status", func() {
+			inst := &scribev1alpha1.ReplicationSource{}
+			Eventually(func() int32 {
+				_ = k8sClient.Get(ctx, utils.NameFor(rs), inst)
+				if inst.Status == nil {
+					return 0
+				}
+				return inst.Status.ConsecutiveFailures
+			}, maxWait, interval).Should(BeNumerically(">=", 1))
+			Expect(inst.Status.LastFailureTime).NotTo(BeNil())
+			cond := inst.Status.Conditions.GetCondition(scribev1alpha1.ConditionSyncFailed)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+			Expect(cond.Reason).NotTo(BeEmpty())
+		})
+		When("a backoff is specified", func() {
+			BeforeEach(func() {
+				rs.Spec.RetryPolicy = &scribev1alpha1.RetryPolicy{
+					Backoff: &metav1.Duration{Duration: time.Hour},
+				}
+			})
+			It("the Job is not recreated until the backoff expires", func() {
+				nsn := utils.NameFor(job)
+				Eventually(func() bool {
+					return kerrors.IsNotFound(k8sClient.Get(ctx, nsn, &batchv1.Job{}))
+				}, maxWait, interval).Should(BeTrue())
+				Consistently(func() bool {
+					return kerrors.IsNotFound(k8sClient.Get(ctx, nsn, &batchv1.Job{}))
+				}, "5s", interval).Should(BeTrue())
+			})
+		})
+	})
+
 	Context("rsync: when no remote address is specified", func() {
 		BeforeEach(func() {
 			rs.Spec.Rsync = &scribev1alpha1.ReplicationSourceRsyncSpec{
@@ -0,0 +1,541 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-lib/status"
+	"golang.org/x/crypto/bcrypt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+	"github.com/backube/scribe/controllers/utils"
+)
+
+// DefaultRestServerContainerImage is the default container image for the
+// ResticRepositoryServer
+const DefaultRestServerContainerImage = "docker.io/restic/rest-server:0.10.0"
+
+// RestServerContainerImage is the container image of the ResticRepositoryServer
+var RestServerContainerImage string
+
+const (
+	restServerPort         = 8000
+	restServerDataPath     = "/data"
+	restServerHtpasswdPath = "/htpasswd"
+	htpasswdKey            = ".htpasswd"
+)
+
+// ResticRepositoryServerReconciler reconciles a ResticRepositoryServer object
+type ResticRepositoryServerReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+//nolint:lll
+//+kubebuilder:rbac:groups=scribe.backube,resources=resticrepositoryservers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=scribe.backube,resources=resticrepositoryservers/finalizers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=scribe.backube,resources=resticrepositoryservers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+
+func (r *ResticRepositoryServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("resticrepositoryserver", req.NamespacedName)
+	inst := &scribev1alpha1.ResticRepositoryServer{}
+	if err := r.Client.Get(ctx, req.NamespacedName, inst); err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Error(err, "Failed to get ResticRepositoryServer")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if inst.Status == nil {
+		inst.Status = &scribev1alpha1.ResticRepositoryServerStatus{}
+	}
+	if inst.Status.Conditions == nil {
+		inst.Status.Conditions = status.Conditions{}
+	}
+
+	s := &restServer{
+		Context: ctx,
+		Client:  r.Client,
+		Owner:   inst,
+	}
+	_, err := utils.ReconcileBatch(logger,
+		s.ensurePVC,
+		s.ensureCredentials,
+		s.ensureDeployment,
+		s.ensureService,
+	)
+
+	// Set reconcile status condition
+	if err == nil {
+		inst.Status.Conditions.SetCondition(
+			status.Condition{
+				Type:    scribev1alpha1.ConditionReconciled,
+				Status:  corev1.ConditionTrue,
+				Reason:  scribev1alpha1.ReconciledReasonComplete,
+				Message: "Reconcile complete",
+			})
+	} else {
+		inst.Status.Conditions.SetCondition(
+			status.Condition{
+				Type:    scribev1alpha1.ConditionReconciled,
+				Status:  corev1.ConditionFalse,
+				Reason:  scribev1alpha1.ReconciledReasonError,
+				Message: err.Error(),
+			})
+	}
+
+	// Update instance status
+	statusErr := r.Client.Status().Update(ctx, inst)
+	if err == nil { // Don't mask previous error
+		err = statusErr
+	}
+	return ctrl.Result{}, err
+}
+
+func (r *ResticRepositoryServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The credentials are generated for the sources and destinations that
+	// refer to a server, so they need to trigger its reconcile
+	referringServer := handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		ref := repositoryServerRef(obj)
+		if ref == nil {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{
+			Name:      ref.Name,
+			Namespace: obj.GetNamespace(),
+		}}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&scribev1alpha1.ResticRepositoryServer{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.Service{}).
+		Watches(&source.Kind{Type: &scribev1alpha1.ReplicationSource{}}, referringServer).
+		Watches(&source.Kind{Type: &scribev1alpha1.ReplicationDestination{}}, referringServer).
+		Complete(r)
+}
+
+// repositoryServerRef returns the ResticRepositoryServer that is used by a
+// ReplicationSource or ReplicationDestination, if any
+func repositoryServerRef(obj client.Object) *scribev1alpha1.ResticRepositoryServerReference {
+	switch o := obj.(type) {
+	case *scribev1alpha1.ReplicationSource:
+		if o.Spec.Restic != nil {
+			return o.Spec.Restic.RepositoryServer
+		}
+	case *scribev1alpha1.ReplicationDestination:
+		if o.Spec.Restic != nil {
+			return o.Spec.Restic.RepositoryServer
+		}
+	}
+	return nil
+}
+
+// restServer holds the state of a ResticRepositoryServer's reconcile
+type restServer struct {
+	Context context.Context
+	Client  client.Client
+	Owner   *scribev1alpha1.ResticRepositoryServer
+}
+
+func (s *restServer) name() string {
+	return "scribe-rest-" + s.Owner.Name
+}
+
+func (s *restServer) selector() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      s.name(),
+		"app.kubernetes.io/component": "restic-rest-server",
+		"app.kubernetes.io/part-of":   "scribe",
+	}
+}
+
+// ensurePVC provisions the volume that holds the repositories. It isn't
+// owned by the server so that the backups outlive it.
+func (s *restServer) ensurePVC(l logr.Logger) (bool, error) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.name(),
+			Namespace: s.Owner.Namespace,
+		},
+	}
+	logger := l.WithValues("PVC", utils.NameFor(pvc))
+	op, err := ctrlutil.CreateOrUpdate(s.Context, s.Client, pvc, func() error {
+		if pvc.CreationTimestamp.IsZero() {
+			pvc.Spec.AccessModes = s.Owner.Spec.AccessModes
+			if len(pvc.Spec.AccessModes) == 0 {
+				pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+			}
+			pvc.Spec.StorageClassName = s.Owner.Spec.StorageClassName
+		}
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		// The volume may be expanded, but never shrunk
+		current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current.Cmp(s.Owner.Spec.Capacity) < 0 {
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = s.Owner.Spec.Capacity
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "PVC reconcile failed")
+		return false, err
+	}
+
+	logger.V(1).Info("PVC reconciled", "operation", op)
+	return true, nil
+}
+
+// ensureCredentials generates the credentials of each repository that is
+// used by a ReplicationSource or ReplicationDestination, and publishes them
+// to the server via its htpasswd Secret. Credentials are retained after the
+// CRs that use them are removed so that the backups remain accessible.
+func (s *restServer) ensureCredentials(l logr.Logger) (bool, error) {
+	repositories, err := s.referencedRepositories()
+	if err != nil {
+		l.Error(err, "unable to find the repositories of the server")
+		return false, err
+	}
+	credentials := &corev1.SecretList{}
+	if err := s.Client.List(s.Context, credentials, client.InNamespace(s.Owner.Namespace),
+		client.MatchingLabels{utils.RepositoryServerLabel: s.Owner.Name}); err != nil {
+		l.Error(err, "unable to list repository credentials")
+		return false, err
+	}
+	passwords := map[string][]byte{}
+	renewed := map[string]bool{}
+	for _, secret := range credentials.Items {
+		repository := secret.Labels[utils.RepositoryLabel]
+		if password, ok := secret.Data[restPasswordKey]; ok && repository != "" &&
+			secret.Name == utils.RepositoryCredentialsName(s.Owner.Name, repository) {
+			passwords[repository] = password
+		}
+	}
+	for repository := range repositories {
+		if _, ok := passwords[repository]; ok {
+			continue
+		}
+		password, err := s.createCredentials(l, repository)
+		if err != nil {
+			return false, err
+		}
+		passwords[repository] = password
+		renewed[repository] = true
+	}
+
+	htpasswd := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.name(),
+			Namespace: s.Owner.Namespace,
+		},
+	}
+	logger := l.WithValues("Secret", utils.NameFor(htpasswd))
+	op, err := ctrlutil.CreateOrUpdate(s.Context, s.Client, htpasswd, func() error {
+		if err := ctrl.SetControllerReference(s.Owner, htpasswd, s.Client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		contents, err := updateHtpasswd(htpasswd.Data[htpasswdKey], passwords, renewed)
+		if err != nil {
+			logger.Error(err, "unable to hash the repository credentials")
+			return err
+		}
+		htpasswd.Data = map[string][]byte{htpasswdKey: contents}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "htpasswd Secret reconcile failed")
+		return false, err
+	}
+	logger.V(1).Info("htpasswd Secret reconciled", "operation", op)
+
+	s.Owner.Status.Repositories = []string{}
+	for repository := range passwords {
+		s.Owner.Status.Repositories = append(s.Owner.Status.Repositories, repository)
+	}
+	sort.Strings(s.Owner.Status.Repositories)
+	return true, nil
+}
+
+// referencedRepositories finds the repositories on the server that are used
+// by ReplicationSources and ReplicationDestinations
+func (s *restServer) referencedRepositories() (map[string]bool, error) {
+	repositories := map[string]bool{}
+	sources := &scribev1alpha1.ReplicationSourceList{}
+	if err := s.Client.List(s.Context, sources, client.InNamespace(s.Owner.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range sources.Items {
+		if ref := repositoryServerRef(&sources.Items[i]); ref != nil && ref.Name == s.Owner.Name {
+			repositories[utils.RepositoryName(&sources.Items[i], ref)] = true
+		}
+	}
+	destinations := &scribev1alpha1.ReplicationDestinationList{}
+	if err := s.Client.List(s.Context, destinations, client.InNamespace(s.Owner.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range destinations.Items {
+		if ref := repositoryServerRef(&destinations.Items[i]); ref != nil && ref.Name == s.Owner.Name {
+			repositories[utils.RepositoryName(&destinations.Items[i], ref)] = true
+		}
+	}
+	return repositories, nil
+}
+
+const (
+	// restUserKey and restPasswordKey are the keys of the credentials Secret
+	// that hold the repository's user on the server and its password. The
+	// password is distinct from RESTIC_PASSWORD, which encrypts the
+	// repository. They are kept out of RESTIC_REPOSITORY so that the URL
+	// doesn't carry them, and the mover adds them when connecting.
+	restUserKey     = "RESTIC_REST_USERNAME"
+	restPasswordKey = "RESTIC_REST_PASSWORD"
+	// passwordBytes is the number of random bytes in a generated password
+	passwordBytes = 24
+)
+
+func generatePassword() (string, error) {
+	b := make([]byte, passwordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// createCredentials generates the Secret that allows the restic mover to use
+// a repository. The repository's user is the name of the repository, and the
+// server restricts it to the repository's path.
+func (s *restServer) createCredentials(l logr.Logger, repository string) ([]byte, error) {
+	password, err := generatePassword()
+	if err != nil {
+		return nil, err
+	}
+	encryptionPassword, err := generatePassword()
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.RepositoryCredentialsName(s.Owner.Name, repository),
+			Namespace: s.Owner.Namespace,
+			Labels: map[string]string{
+				utils.RepositoryServerLabel: s.Owner.Name,
+				utils.RepositoryLabel:       repository,
+			},
+		},
+		StringData: map[string]string{
+			// The server doesn't use TLS, so the credentials and requests
+			// cross the cluster network unencrypted. The backup data itself
+			// is encrypted by restic with RESTIC_PASSWORD.
+			"RESTIC_REPOSITORY": fmt.Sprintf("rest:http://%s.%s.svc:%d/%s/",
+				s.name(), s.Owner.Namespace, restServerPort, repository),
+			"RESTIC_PASSWORD": encryptionPassword,
+			restUserKey:       repository,
+			restPasswordKey:   password,
+		},
+	}
+	logger := l.WithValues("Secret", utils.NameFor(secret))
+	if err := s.Client.Create(s.Context, secret); err != nil {
+		logger.Error(err, "unable to create repository credentials")
+		return nil, err
+	}
+	logger.Info("repository credentials created", "repository", repository)
+	return []byte(password), nil
+}
+
+// updateHtpasswd regenerates the contents of the htpasswd file for the
+// repositories' passwords. The existing entries of the users whose passwords
+// haven't been renewed are kept, since rehashing is slow and the salt would
+// change the file each time.
+func updateHtpasswd(current []byte, passwords map[string][]byte, renewed map[string]bool) ([]byte, error) {
+	hashes := map[string]string{}
+	for _, line := range strings.Split(string(current), "\n") {
+		if user := strings.SplitN(line, ":", 2); len(user) == 2 {
+			hashes[user[0]] = user[1]
+		}
+	}
+	users := []string{}
+	for user := range passwords {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	var contents strings.Builder
+	for _, user := range users {
+		hash, ok := hashes[user]
+		if !ok || renewed[user] {
+			newHash, err := bcrypt.GenerateFromPassword(passwords[user], bcrypt.DefaultCost)
+			if err != nil {
+				return nil, err
+			}
+			hash = string(newHash)
+		}
+		fmt.Fprintf(&contents, "%s:%s\n", user, hash)
+	}
+	return []byte(contents.String()), nil
+}
+
+//nolint:funlen
+func (s *restServer) ensureDeployment(l logr.Logger) (bool, error) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.name(),
+			Namespace: s.Owner.Namespace,
+		},
+	}
+	logger := l.WithValues("Deployment", utils.NameFor(deployment))
+	op, err := ctrlutil.CreateOrUpdate(s.Context, s.Client, deployment, func() error {
+		if err := ctrl.SetControllerReference(s.Owner, deployment, s.Client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		replicas := int32(1)
+		if s.Owner.Spec.Paused {
+			replicas = 0
+		}
+		deployment.Spec.Replicas = &replicas
+		// The volume can't be shared by the old and new pods
+		deployment.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: s.selector()}
+		deployment.Spec.Template.ObjectMeta.Labels = s.selector()
+
+		command := []string{"rest-server",
+			"--listen", fmt.Sprintf(":%d", restServerPort),
+			"--path", restServerDataPath,
+			"--htpasswd-file", restServerHtpasswdPath + "/" + htpasswdKey,
+			"--private-repos",
+		}
+		if s.Owner.Spec.AppendOnly {
+			command = append(command, "--append-only")
+		}
+		podSpec := &deployment.Spec.Template.Spec
+		podSpec.Containers = []corev1.Container{{
+			Name:    "rest-server",
+			Image:   RestServerContainerImage,
+			Command: command,
+			Ports: []corev1.ContainerPort{{
+				Name:          "http",
+				ContainerPort: restServerPort,
+				Protocol:      corev1.ProtocolTCP,
+			}},
+			ReadinessProbe: &corev1.Probe{
+				Handler: corev1.Handler{
+					TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(restServerPort)},
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "data", MountPath: restServerDataPath},
+				// The whole directory is mounted so that the server sees
+				// changes to the credentials
+				{Name: "htpasswd", MountPath: restServerHtpasswdPath, ReadOnly: true},
+			},
+		}}
+		podSpec.Volumes = []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: s.name()},
+			}},
+			{Name: "htpasswd", VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: s.name()},
+			}},
+		}
+		mover.ApplyMoverConfig(podSpec, s.Owner.Spec.PodConfig)
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Deployment reconcile failed")
+		return false, err
+	}
+	logger.V(1).Info("Deployment reconciled", "operation", op)
+
+	if deployment.Status.AvailableReplicas > 0 {
+		s.Owner.Status.Conditions.SetCondition(status.Condition{
+			Type:    scribev1alpha1.ConditionServerAvailable,
+			Status:  corev1.ConditionTrue,
+			Reason:  scribev1alpha1.ServerAvailableReasonAvailable,
+			Message: "The server is accepting connections",
+		})
+	} else {
+		s.Owner.Status.Conditions.SetCondition(status.Condition{
+			Type:    scribev1alpha1.ConditionServerAvailable,
+			Status:  corev1.ConditionFalse,
+			Reason:  scribev1alpha1.ServerAvailableReasonUnavailable,
+			Message: "Waiting for the server to start",
+		})
+	}
+	return true, nil
+}
+
+func (s *restServer) ensureService(l logr.Logger) (bool, error) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.name(),
+			Namespace: s.Owner.Namespace,
+		},
+	}
+	logger := l.WithValues("Service", utils.NameFor(service))
+	op, err := ctrlutil.CreateOrUpdate(s.Context, s.Client, service, func() error {
+		if err := ctrl.SetControllerReference(s.Owner, service, s.Client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		service.Spec.Selector = s.selector()
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		if len(service.Spec.Ports) != 1 {
+			service.Spec.Ports = []corev1.ServicePort{{}}
+		}
+		service.Spec.Ports[0].Name = "http"
+		service.Spec.Ports[0].Port = restServerPort
+		service.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+		service.Spec.Ports[0].TargetPort = intstr.FromInt(restServerPort)
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Service reconcile failed")
+		return false, err
+	}
+	logger.V(1).Info("Service reconciled", "operation", op)
+
+	s.Owner.Status.Address = fmt.Sprintf("http://%s.%s.svc:%d", service.Name, service.Namespace, restServerPort)
+	return true, nil
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/utils"
+)
+
+var _ = Describe("Restic repository server htpasswd", func() {
+	passwords := map[string][]byte{
+		"one": []byte("password1"),
+		"two": []byte("password2"),
+	}
+
+	It("has a valid entry for each repository", func() {
+		contents, err := updateHtpasswd(nil, passwords, map[string]bool{})
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		Expect(lines).To(HaveLen(2))
+		for i, user := range []string{"one", "two"} {
+			entry := strings.SplitN(lines[i], ":", 2)
+			Expect(entry[0]).To(Equal(user))
+			Expect(bcrypt.CompareHashAndPassword([]byte(entry[1]), passwords[user])).To(Succeed())
+		}
+	})
+	It("keeps the existing entries", func() {
+		contents, err := updateHtpasswd(nil, passwords, map[string]bool{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updateHtpasswd(contents, passwords, map[string]bool{})).To(Equal(contents))
+	})
+	It("rehashes the renewed passwords", func() {
+		contents, err := updateHtpasswd(nil, passwords, map[string]bool{})
+		Expect(err).NotTo(HaveOccurred())
+		renewed := map[string][]byte{
+			"one": []byte("password1"),
+			"two": []byte("password3"),
+		}
+		updated, err := updateHtpasswd(contents, renewed, map[string]bool{"two": true})
+		Expect(err).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(string(updated)), "\n")
+		Expect(lines[0]).To(Equal(strings.Split(string(contents), "\n")[0]))
+		entry := strings.SplitN(lines[1], ":", 2)
+		Expect(bcrypt.CompareHashAndPassword([]byte(entry[1]), renewed["two"])).To(Succeed())
+	})
+	It("removes the repositories that no longer have credentials", func() {
+		contents, err := updateHtpasswd(nil, passwords, map[string]bool{})
+		Expect(err).NotTo(HaveOccurred())
+		updated, err := updateHtpasswd(contents, map[string][]byte{"one": passwords["one"]}, map[string]bool{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(updated)).NotTo(ContainSubstring("two:"))
+	})
+})
+
+var _ = Describe("ResticRepositoryServer", func() {
+	var ctx = context.Background()
+	var namespace *corev1.Namespace
+	var server *scribev1alpha1.ResticRepositoryServer
+
+	BeforeEach(func() {
+		// Each test is run in its own namespace
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "scribe-test-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+		Expect(namespace.Name).NotTo(BeEmpty())
+
+		server = &scribev1alpha1.ResticRepositoryServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "server",
+				Namespace: namespace.Name,
+			},
+			Spec: scribev1alpha1.ResticRepositoryServerSpec{
+				Capacity: resource.MustParse("5Gi"),
+			},
+		}
+	})
+	AfterEach(func() {
+		// All resources are namespaced, so this should clean it all up
+		Expect(k8sClient.Delete(ctx, namespace)).To(Succeed())
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, server)).To(Succeed())
+	})
+
+	It("deploys the server", func() {
+		pvc := &corev1.PersistentVolumeClaim{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rest-server", Namespace: namespace.Name}, pvc)
+		}, maxWait, interval).Should(Succeed())
+		Expect(*pvc.Spec.Resources.Requests.Storage()).To(Equal(resource.MustParse("5Gi")))
+		Expect(pvc.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+		// The repositories outlive the server
+		Expect(pvc.OwnerReferences).To(BeEmpty())
+
+		deployment := &appsv1.Deployment{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rest-server", Namespace: namespace.Name}, deployment)
+		}, maxWait, interval).Should(Succeed())
+		Expect(deployment).To(beOwnedBy(server))
+		command := deployment.Spec.Template.Spec.Containers[0].Command
+		Expect(command).To(ContainElement("--private-repos"))
+		Expect(command).NotTo(ContainElement("--append-only"))
+
+		service := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rest-server", Namespace: namespace.Name}, service)
+		}, maxWait, interval).Should(Succeed())
+		Expect(service).To(beOwnedBy(server))
+		Expect(service.Spec.Selector).To(Equal(deployment.Spec.Template.Labels))
+
+		Eventually(func() string {
+			Expect(k8sClient.Get(ctx, utils.NameFor(server), server)).To(Succeed())
+			if server.Status == nil {
+				return ""
+			}
+			return server.Status.Address
+		}, maxWait, interval).Should(Equal("http://scribe-rest-server." + namespace.Name + ".svc:8000"))
+		// There's no kubelet to start the server
+		Expect(server.Status.Conditions.IsFalseFor(scribev1alpha1.ConditionServerAvailable)).To(BeTrue())
+	})
+
+	When("appendOnly is set", func() {
+		BeforeEach(func() {
+			server.Spec.AppendOnly = true
+		})
+		It("the server refuses to remove data", func() {
+			deployment := &appsv1.Deployment{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rest-server", Namespace: namespace.Name}, deployment)
+			}, maxWait, interval).Should(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Command).To(ContainElement("--append-only"))
+		})
+	})
+
+	When("a ReplicationSource uses the server", func() {
+		var rs *scribev1alpha1.ReplicationSource
+		BeforeEach(func() {
+			rs = &scribev1alpha1.ReplicationSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mysource",
+					Namespace: namespace.Name,
+				},
+				Spec: scribev1alpha1.ReplicationSourceSpec{
+					SourcePVC: "data",
+					Restic: &scribev1alpha1.ReplicationSourceResticSpec{
+						RepositoryServer: &scribev1alpha1.ResticRepositoryServerReference{
+							Name: server.Name,
+						},
+					},
+				},
+			}
+		})
+		JustBeforeEach(func() {
+			Expect(k8sClient.Create(ctx, rs)).To(Succeed())
+		})
+		It("generates credentials for the source", func() {
+			credentials := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rest-server-mysource", Namespace: namespace.Name}, credentials)
+			}, maxWait, interval).Should(Succeed())
+			Expect(credentials.Labels).To(HaveKeyWithValue(utils.RepositoryServerLabel, "server"))
+			Expect(credentials.Labels).To(HaveKeyWithValue(utils.RepositoryLabel, "mysource"))
+			// The credentials are retained with the repository
+			Expect(credentials.OwnerReferences).To(BeEmpty())
+			Expect(credentials.Data).To(HaveKey("RESTIC_PASSWORD"))
+			password := string(credentials.Data[restPasswordKey])
+			Expect(password).NotTo(BeEmpty())
+			Expect(string(credentials.Data[restUserKey])).To(Equal("mysource"))
+			// The credentials aren't embedded in the URL
+			Expect(string(credentials.Data["RESTIC_REPOSITORY"])).To(Equal(
+				"rest:http://scribe-rest-server." + namespace.Name + ".svc:8000/mysource/"))
+
+			htpasswd := &corev1.Secret{}
+			Eventually(func() []byte {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rest-server", Namespace: namespace.Name}, htpasswd)).To(Succeed())
+				return htpasswd.Data[htpasswdKey]
+			}, maxWait, interval).ShouldNot(BeEmpty())
+			entry := strings.SplitN(strings.TrimSpace(string(htpasswd.Data[htpasswdKey])), ":", 2)
+			Expect(entry[0]).To(Equal("mysource"))
+			Expect(bcrypt.CompareHashAndPassword([]byte(entry[1]), []byte(password))).To(Succeed())
+
+			Eventually(func() []string {
+				Expect(k8sClient.Get(ctx, utils.NameFor(server), server)).To(Succeed())
+				if server.Status == nil {
+					return nil
+				}
+				return server.Status.Repositories
+			}, maxWait, interval).Should(ConsistOf("mysource"))
+		})
+		It("keeps the credentials after the source is deleted", func() {
+			credentials := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: "scribe-rest-server-mysource", Namespace: namespace.Name}, credentials)
+			}, maxWait, interval).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, rs)).To(Succeed())
+			// Trigger a reconcile of the server
+			Eventually(func() error {
+				Expect(k8sClient.Get(ctx, utils.NameFor(server), server)).To(Succeed())
+				server.Spec.Paused = true
+				return k8sClient.Update(ctx, server)
+			}, maxWait, interval).Should(Succeed())
+			Consistently(func() []string {
+				Expect(k8sClient.Get(ctx, utils.NameFor(server), server)).To(Succeed())
+				if server.Status == nil {
+					return nil
+				}
+				return server.Status.Repositories
+			}, duration, interval).Should(ConsistOf("mysource"))
+		})
+	})
+})
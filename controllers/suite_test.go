@@ -38,6 +38,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover/rclone"
+	"github.com/backube/scribe/controllers/mover/rsync"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -45,6 +47,8 @@ const (
 	duration = 10 * time.Second
 	maxWait  = 60 * time.Second
 	interval = 250 * time.Millisecond
+	// dataVolumeName is the name of the Job volume that holds the data PVC
+	dataVolumeName = "data"
 )
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
@@ -65,6 +69,10 @@ func TestAPIs(t *testing.T) {
 var _ = BeforeSuite(func(done Done) {
 	logf.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)))
 
+	// Register the data movers
+	rclone.Register()
+	rsync.Register()
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths: []string{
@@ -116,6 +124,13 @@ var _ = BeforeSuite(func(done Done) {
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&ResticRepositoryServerReconciler{
+		Client: k8sManager.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ResticRepositoryServer"),
+		Scheme: k8sManager.GetScheme(),
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	go func() {
 		defer GinkgoRecover()
 		err = k8sManager.Start(ctrl.SetupSignalHandler())
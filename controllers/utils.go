@@ -18,23 +18,32 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package controllers
 
 import (
+	"fmt"
 	"time"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
 	"github.com/go-logr/logr"
+	"github.com/operator-framework/operator-lib/status"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const (
-	metricsNamespace = "scribe"
+	metricsNamespace = mover.MetricsNamespace
 )
 
 // scribeMetrics holds references to fully qualified instances of the metrics
 type scribeMetrics struct {
-	MissedIntervals prometheus.Counter
-	OutOfSync       prometheus.Gauge
-	SyncDurations   prometheus.Observer
+	MissedIntervals  prometheus.Counter
+	OutOfSync        prometheus.Gauge
+	SyncDurations    prometheus.Observer
+	BytesTransferred prometheus.Counter
+	FilesChanged     prometheus.Counter
+	VolumeBytes      prometheus.Gauge
+	VolumeFiles      prometheus.Gauge
 }
 
 var (
@@ -71,19 +80,56 @@ var (
 		},
 		metricLabels,
 	)
+	bytesTransferred = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "sync_transferred_bytes_total",
+			Namespace: metricsNamespace,
+			Help:      "The amount of data transferred by completed synchronizations, in bytes",
+		},
+		metricLabels,
+	)
+	filesChanged = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "sync_changed_files_total",
+			Namespace: metricsNamespace,
+			Help:      "The number of files created or modified by completed synchronizations",
+		},
+		metricLabels,
+	)
+	volumeBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "volume_size_bytes",
+			Namespace: metricsNamespace,
+			Help:      "The amount of data replicated by the most recent synchronization, in bytes",
+		},
+		metricLabels,
+	)
+	volumeFiles = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "volume_files",
+			Namespace: metricsNamespace,
+			Help:      "The number of files replicated by the most recent synchronization",
+		},
+		metricLabels,
+	)
 )
 
 func newScribeMetrics(labels prometheus.Labels) scribeMetrics {
 	return scribeMetrics{
-		MissedIntervals: missedIntervals.With(labels),
-		OutOfSync:       outOfSync.With(labels),
-		SyncDurations:   syncDurations.With(labels),
+		MissedIntervals:  missedIntervals.With(labels),
+		OutOfSync:        outOfSync.With(labels),
+		SyncDurations:    syncDurations.With(labels),
+		BytesTransferred: bytesTransferred.With(labels),
+		FilesChanged:     filesChanged.With(labels),
+		VolumeBytes:      volumeBytes.With(labels),
+		VolumeFiles:      volumeFiles.With(labels),
 	}
 }
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(missedIntervals, outOfSync, syncDurations)
+	metrics.Registry.MustRegister(missedIntervals, outOfSync, syncDurations,
+		bytesTransferred, filesChanged, volumeBytes, volumeFiles)
 }
 
 //nolint:funlen
@@ -127,3 +173,77 @@ func (r *ReplicationDestinationReconciler) countReplicationMethods(instance *scr
 	logger.Info("Counting over ", "Number of Replication Methods: ", numOfReplication)
 	return numOfReplication
 }
+
+// retryBackoff returns how much longer to wait before the data mover may be
+// retried following its most recent failure.
+func retryBackoff(policy *scribev1alpha1.RetryPolicy, failures int32, lastFailure *metav1.Time) time.Duration {
+	if failures == 0 || lastFailure == nil || policy == nil || policy.Backoff == nil {
+		return 0
+	}
+	return time.Until(lastFailure.Add(policy.Backoff.Duration))
+}
+
+// syncAbandoned returns true if the most recent synchronization iteration was
+// abandoned because the data mover failed too many times. Abandoning an
+// iteration resets the failure count, so this is the case when a failure
+// occurred after the last successful synchronization, but none have been
+// counted since.
+func syncAbandoned(failures int32, lastFailure *metav1.Time, lastSync *metav1.Time) bool {
+	return failures == 0 && lastFailure != nil &&
+		(lastSync.IsZero() || lastFailure.After(lastSync.Time))
+}
+
+// recordSyncFailure updates the status of a CR following a failure of its data
+// mover. It returns true if the current synchronization iteration should be
+// abandoned, in which case the failure count is reset for the next one.
+func recordSyncFailure(policy *scribev1alpha1.RetryPolicy, failures *int32, lastFailure **metav1.Time,
+	conditions *status.Conditions, failure *mover.Failure) bool {
+	*failures++
+	*lastFailure = &metav1.Time{Time: time.Now()}
+	abandon := policy != nil && policy.MaxAttempts != nil && *policy.MaxAttempts >= 1 &&
+		*failures >= *policy.MaxAttempts
+	message := failure.Message
+	if abandon {
+		message = fmt.Sprintf("giving up after %d attempts: %s", *failures, message)
+		*failures = 0
+	}
+	conditions.SetCondition(status.Condition{
+		Type:    scribev1alpha1.ConditionSyncFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  status.ConditionReason(failure.Reason),
+		Message: message,
+	})
+	return abandon
+}
+
+// recordSyncSuccess updates the status of a CR following a successful
+// synchronization.
+func recordSyncSuccess(failures *int32, conditions *status.Conditions) {
+	*failures = 0
+	conditions.SetCondition(status.Condition{
+		Type:    scribev1alpha1.ConditionSyncFailed,
+		Status:  corev1.ConditionFalse,
+		Reason:  scribev1alpha1.SyncFailedReasonSucceeded,
+		Message: "Synchronization completed",
+	})
+}
+
+// recordSyncResult exports the numbers from the result of a completed
+// synchronization as metrics.
+func recordSyncResult(result *scribev1alpha1.SyncResult, metrics scribeMetrics) {
+	if result == nil {
+		return
+	}
+	if result.BytesTransferred != nil {
+		metrics.BytesTransferred.Add(float64(*result.BytesTransferred))
+	}
+	if result.FilesChanged != nil {
+		metrics.FilesChanged.Add(float64(*result.FilesChanged))
+	}
+	if result.BytesTotal != nil {
+		metrics.VolumeBytes.Set(float64(*result.BytesTotal))
+	}
+	if result.FilesTotal != nil {
+		metrics.VolumeFiles.Set(float64(*result.FilesTotal))
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -62,3 +64,44 @@ func CleanupObjects(ctx context.Context, c client.Client,
 	}
 	return nil
 }
+
+// MarkOldSnapshotForCleanup marks the VolumeSnapshot referenced by "oldImage"
+// for deletion if it is being replaced by "latestImage". Only snapshots that
+// are controlled by "owner" are marked, and a missing snapshot is not an error.
+func MarkOldSnapshotForCleanup(ctx context.Context, c client.Client, logger logr.Logger,
+	owner metav1.Object, oldImage, latestImage *corev1.TypedLocalObjectReference) error {
+	// Make sure we only delete an old snapshot (it's a snapshot, but not the
+	// current LatestImage)
+	if oldImage == nil || oldImage.Kind != "VolumeSnapshot" || oldImage.APIGroup == nil ||
+		*oldImage.APIGroup != snapv1.SchemeGroupVersion.Group {
+		return nil
+	}
+	if latestImage != nil && latestImage.Kind == oldImage.Kind && latestImage.Name == oldImage.Name {
+		return nil
+	}
+
+	snap := &snapv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      oldImage.Name,
+			Namespace: owner.GetNamespace(),
+		},
+	}
+	l := logger.WithValues("snapshot", NameFor(snap))
+	if err := c.Get(ctx, NameFor(snap), snap); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			l.Error(err, "unable to get old snapshot")
+		}
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(snap, owner) {
+		l.V(1).Info("not marking snapshot for cleanup -- not owned by us")
+		return nil
+	}
+	MarkForCleanup(owner, snap)
+	if err := c.Update(ctx, snap); err != nil {
+		l.Error(err, "unable to mark old snapshot for cleanup")
+		return err
+	}
+	l.V(1).Info("marked old snapshot for cleanup")
+	return nil
+}
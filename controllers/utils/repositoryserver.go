@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+const (
+	// RepositoryServerLabel is placed on the Secrets holding the credentials
+	// of a ResticRepositoryServer's repositories. Its value is the name of
+	// the server.
+	RepositoryServerLabel = "scribe.backube/restic-repository-server"
+	// RepositoryLabel is placed on the Secrets holding the credentials of a
+	// ResticRepositoryServer's repositories. Its value is the name of the
+	// repository.
+	RepositoryLabel = "scribe.backube/restic-repository"
+)
+
+// RepositoryName is the name of the repository on a ResticRepositoryServer
+// that is used by a ReplicationSource or ReplicationDestination
+func RepositoryName(owner metav1.Object, ref *scribev1alpha1.ResticRepositoryServerReference) string {
+	if ref.Repository != nil {
+		return *ref.Repository
+	}
+	return owner.GetName()
+}
+
+// RepositoryCredentialsName is the name of the Secret that holds the
+// credentials for a repository on a ResticRepositoryServer
+func RepositoryCredentialsName(server string, repository string) string {
+	return "scribe-rest-" + server + "-" + repository
+}
@@ -36,10 +36,12 @@ const DefaultSCCName = "scribe-mover"
 var SCCName string
 
 type SAHandler struct {
-	Context     context.Context
-	Client      client.Client
-	SA          *corev1.ServiceAccount
-	Owner       metav1.Object
+	Context context.Context
+	Client  client.Client
+	SA      *corev1.ServiceAccount
+	Owner   metav1.Object
+	// ExtraRules are granted to the SA in addition to the use of the SCC
+	ExtraRules  []rbacv1.PolicyRule
 	role        *rbacv1.Role
 	roleBinding *rbacv1.RoleBinding
 }
@@ -102,6 +104,7 @@ func (d *SAHandler) ensureRole(l logr.Logger) (bool, error) {
 				Verbs:         []string{"use"},
 			},
 		}
+		d.role.Rules = append(d.role.Rules, d.ExtraRules...)
 		return nil
 	})
 	if err != nil {
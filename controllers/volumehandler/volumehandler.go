@@ -109,13 +109,7 @@ func (vh *VolumeHandler) EnsureNewPVC(ctx context.Context, log logr.Logger,
 
 	// Ensure required configuration parameters have been provided in order to
 	// create volume
-	if len(vh.accessModes) == 0 {
-		err := errors.New("accessModes must be provided when destinationPVC is not")
-		logger.Error(err, "error allocating new PVC")
-		return nil, err
-	}
-	if vh.capacity == nil {
-		err := errors.New("capacity must be provided when destinationPVC is not")
+	if err := vh.validateNewPVC(); err != nil {
 		logger.Error(err, "error allocating new PVC")
 		return nil, err
 	}
@@ -153,6 +147,41 @@ func (vh *VolumeHandler) EnsureNewPVC(ctx context.Context, log logr.Logger,
 	return pvc, nil
 }
 
+// NewPVCTemplate returns a template for a PVC with the same properties as those
+// created by EnsureNewPVC. It can be used to provision a generic ephemeral
+// volume for a Pod.
+func (vh *VolumeHandler) NewPVCTemplate(log logr.Logger) (*v1.PersistentVolumeClaimTemplate, error) {
+	if err := vh.validateNewPVC(); err != nil {
+		log.Error(err, "error creating PVC template")
+		return nil, err
+	}
+	volumeMode := v1.PersistentVolumeFilesystem
+	return &v1.PersistentVolumeClaimTemplate{
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      vh.accessModes,
+			StorageClassName: vh.storageClassName,
+			VolumeMode:       &volumeMode,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: *vh.capacity,
+				},
+			},
+		},
+	}, nil
+}
+
+// validateNewPVC ensures the parameters required to create a volume have
+// been provided
+func (vh *VolumeHandler) validateNewPVC() error {
+	if len(vh.accessModes) == 0 {
+		return errors.New("accessModes must be provided when destinationPVC is not")
+	}
+	if vh.capacity == nil {
+		return errors.New("capacity must be provided when destinationPVC is not")
+	}
+	return nil
+}
+
 func (vh *VolumeHandler) SetAccessModes(accessModes []v1.PersistentVolumeAccessMode) {
 	vh.accessModes = accessModes
 }
@@ -161,6 +190,38 @@ func (vh *VolumeHandler) GetAccessModes() []v1.PersistentVolumeAccessMode {
 	return vh.accessModes
 }
 
+func (vh *VolumeHandler) GetCopyMethod() scribev1alpha1.CopyMethodType {
+	return vh.copyMethod
+}
+
+// RemoveSnapshotAnnotationFromPVC removes the annotation that EnsureImage uses
+// to track the name of the snapshot it creates. Once removed, the next call to
+// EnsureImage will create a new snapshot instead of returning the existing
+// one. A missing PVC is not treated as an error.
+func (vh *VolumeHandler) RemoveSnapshotAnnotationFromPVC(ctx context.Context, log logr.Logger,
+	pvcName string) error {
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvcName,
+			Namespace: vh.owner.GetNamespace(),
+		},
+	}
+	logger := log.WithValues("PVC", utils.NameFor(pvc))
+	if err := vh.client.Get(ctx, utils.NameFor(pvc), pvc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, ok := pvc.Annotations[snapshotAnnotation]; !ok {
+		return nil
+	}
+	delete(pvc.Annotations, snapshotAnnotation)
+	if err := vh.client.Update(ctx, pvc); err != nil {
+		logger.Error(err, "unable to remove snapshot annotation from PVC")
+		return err
+	}
+	logger.V(1).Info("removed snapshot annotation from PVC")
+	return nil
+}
+
 func (vh *VolumeHandler) ensureImageSnapshot(ctx context.Context, log logr.Logger,
 	src *v1.PersistentVolumeClaim) (*snapv1.VolumeSnapshot, error) {
 	// create & record name (if necessary)
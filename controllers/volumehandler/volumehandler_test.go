@@ -19,6 +19,7 @@ package volumehandler
 
 import (
 	"context"
+	"time"
 
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
 	. "github.com/onsi/ginkgo"
@@ -113,6 +114,22 @@ var _ = Describe("Volumehandler", func() {
 				Expect(*(new.Spec.Resources.Requests.Storage())).To(Equal((capacity)))
 				Expect(new.Name).To(Equal(pvcName))
 			})
+			It("can be used to template an ephemeral volume", func() {
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithOwner(rd),
+					FromDestination(&rd.Spec.Rsync.ReplicationDestinationVolumeOptions),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vh).ToNot(BeNil())
+
+				template, err := vh.NewPVCTemplate(logger)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(template).ToNot(BeNil())
+				Expect(*template.Spec.StorageClassName).To(Equal(customSC))
+				Expect(template.Spec.AccessModes).To(ConsistOf(v1.ReadWriteOnce))
+				Expect(*(template.Spec.Resources.Requests.Storage())).To(Equal((capacity)))
+			})
 		})
 
 		When("CopyMethod is None", func() {
@@ -231,6 +248,66 @@ var _ = Describe("Volumehandler", func() {
 				Expect(tlor.Name).To(Equal(snapname))
 				Expect(*tlor.APIGroup).To(Equal(snapv1.SchemeGroupVersion.Group))
 			})
+
+			It("takes a new snapshot once the previous one is released", func() {
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithOwner(rd),
+					FromDestination(&rd.Spec.Rsync.ReplicationDestinationVolumeOptions),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				pvc := &v1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "mypvc",
+						Namespace: ns.Name,
+					},
+					Spec: v1.PersistentVolumeClaimSpec{
+						AccessModes: []v1.PersistentVolumeAccessMode{
+							v1.ReadWriteOnce,
+						},
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								"storage": resource.MustParse("2Gi"),
+							},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+
+				// The first iteration records its snapshot on the PVC
+				_, err = vh.EnsureImage(ctx, logger, pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, utils.NameFor(pvc), pvc)).To(Succeed())
+				oldSnapName := pvc.Annotations[snapshotAnnotation]
+				Expect(oldSnapName).NotTo(BeEmpty())
+
+				// Once released, the next iteration creates a different one
+				Expect(vh.RemoveSnapshotAnnotationFromPVC(ctx, logger, pvc.Name)).To(Succeed())
+				Expect(k8sClient.Get(ctx, utils.NameFor(pvc), pvc)).To(Succeed())
+				Expect(pvc.Annotations).NotTo(HaveKey(snapshotAnnotation))
+				// Snapshot names are timestamped to the second
+				time.Sleep(time.Second)
+				_, err = vh.EnsureImage(ctx, logger, pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, utils.NameFor(pvc), pvc)).To(Succeed())
+				newSnapName := pvc.Annotations[snapshotAnnotation]
+				Expect(newSnapName).NotTo(BeEmpty())
+				Expect(newSnapName).NotTo(Equal(oldSnapName))
+
+				// Replacing the image marks only the old snapshot for cleanup
+				group := snapv1.SchemeGroupVersion.Group
+				oldImage := &v1.TypedLocalObjectReference{APIGroup: &group, Kind: "VolumeSnapshot", Name: oldSnapName}
+				newImage := &v1.TypedLocalObjectReference{APIGroup: &group, Kind: "VolumeSnapshot", Name: newSnapName}
+				Expect(utils.MarkOldSnapshotForCleanup(ctx, k8sClient, logger, rd, oldImage, newImage)).To(Succeed())
+				Expect(utils.MarkOldSnapshotForCleanup(ctx, k8sClient, logger, rd, newImage, newImage)).To(Succeed())
+				oldSnap := &snapv1.VolumeSnapshot{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: oldSnapName, Namespace: ns.Name}, oldSnap)).To(Succeed())
+				Expect(oldSnap.Labels).To(HaveKeyWithValue("scribe.backube/cleanup", string(rd.UID)))
+				newSnap := &snapv1.VolumeSnapshot{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: newSnapName, Namespace: ns.Name}, newSnap)).To(Succeed())
+				Expect(newSnap.Labels).NotTo(HaveKey("scribe.backube/cleanup"))
+			})
 		})
 	})
 
@@ -37,7 +37,9 @@ import (
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 	"github.com/backube/scribe/controllers"
+	"github.com/backube/scribe/controllers/mover/rclone"
 	"github.com/backube/scribe/controllers/mover/restic"
+	"github.com/backube/scribe/controllers/mover/rsync"
 	"github.com/backube/scribe/controllers/utils"
 	//+kubebuilder:scaffold:imports
 )
@@ -58,7 +60,9 @@ func init() {
 //nolint:funlen
 func main() {
 	// Register the data movers
+	rclone.Register()
 	restic.Register()
+	rsync.Register()
 
 	var metricsAddr string
 	var enableLeaderElection bool
@@ -68,12 +72,10 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	flag.StringVar(&controllers.RcloneContainerImage, "rclone-container-image",
-		controllers.DefaultRcloneContainerImage, "The container image for the rclone data mover")
-	flag.StringVar(&controllers.RsyncContainerImage, "rsync-container-image",
-		controllers.DefaultRsyncContainerImage, "The container image for the rsync data mover")
 	flag.StringVar(&utils.SCCName, "scc-name",
 		utils.DefaultSCCName, "The name of the scribe security context constraint")
+	flag.StringVar(&controllers.RestServerContainerImage, "rest-server-container-image",
+		controllers.DefaultRestServerContainerImage, "The container image for the Restic repository server")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -85,8 +87,6 @@ func main() {
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
 	setupLog.Info(fmt.Sprintf("Operator Version: %s", scribeVersion))
-	setupLog.Info(fmt.Sprintf("Rclone container: %s", controllers.RcloneContainerImage))
-	setupLog.Info(fmt.Sprintf("Rsync container: %s", controllers.RsyncContainerImage))
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -117,6 +117,14 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ReplicationDestination")
 		os.Exit(1)
 	}
+	if err = (&controllers.ResticRepositoryServerReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ResticRepositoryServer"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ResticRepositoryServer")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
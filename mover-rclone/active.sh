@@ -16,18 +16,34 @@ function error {
 [[ -n "${RCLONE_DEST_PATH}" ]] || error 1 "RCLONE_DEST_PATH must be defined"
 [[ -n "${DIRECTION}" ]] || error 1 "DIRECTION must be defined"
 
-RCLONE_FLAGS=(--checksum --one-file-system --create-empty-src-dirs --progress --stats-one-line-date --stats 20s --transfers 10)
+RCLONE_FLAGS=(--checksum --one-file-system --create-empty-src-dirs --use-json-log --stats 20s --transfers 10)
+
+# Run rclone sync, recording the amount of data that it transferred. Its JSON
+# log contains a stats entry every 20s and a final one once it has finished.
+function rclone_sync {
+    local line bytes
+    rclone sync "${RCLONE_FLAGS[@]}" "$@" --log-level DEBUG 2>&1 | while IFS= read -r line; do
+        echo "$line"
+        if [[ $line =~ \"stats\":\{\"bytes\":([0-9]+) ]]; then
+            bytes="${BASH_REMATCH[1]}"
+            if [[ $line =~ \"transfers\":([0-9]+) ]]; then
+                printf '{"bytesTransferred":%d,"filesChanged":%d}\n' "$bytes" "${BASH_REMATCH[1]}" \
+                    > /tmp/sync_result
+            fi
+        fi
+    done
+}
 
 START_TIME=$SECONDS
 case "${DIRECTION}" in
 source)
     getfacl -R "${MOUNT_PATH}" > "${MOUNT_PATH}"/permissons.facl
-    rclone sync "${RCLONE_FLAGS[@]}" "${MOUNT_PATH}" "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" --log-level DEBUG
+    rclone_sync "${MOUNT_PATH}" "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}"
     rm -rf "${MOUNT_PATH}"/permissons.facl
     rc=$?
     ;;
 destination)
-    rclone sync "${RCLONE_FLAGS[@]}" "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" "${MOUNT_PATH}" --log-level DEBUG
+    rclone_sync "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" "${MOUNT_PATH}"
     setfacl --restore="${MOUNT_PATH}"/permissons.facl || true
     rm -rf "${MOUNT_PATH}"/permissons.facl
     rc=$?
@@ -38,4 +54,8 @@ destination)
 esac
 sync
 echo "Rclone completed in $(( SECONDS - START_TIME ))s rc=$rc"
+if [[ $rc -eq 0 && -e /tmp/sync_result ]]; then
+    # Publish the amount of data transferred via the termination message
+    cp /tmp/sync_result /dev/termination-log || true
+fi
 exit "$rc"
@@ -6,8 +6,36 @@ echo "Scribe restic container version: ${version:-unknown}"
 echo  "$@"
 
 
-# Force the associated backup host name to be "scribe"
-RESTIC_HOST="scribe"
+# The host name and tags (RESTIC_HOST and the comma-separated RESTIC_TAGS)
+# identify the snapshots belonging to the source. Older releases always used
+# the host name "scribe".
+SNAPSHOT_FILTER=()
+if [[ -n ${RESTIC_HOST} ]]; then
+    SNAPSHOT_FILTER+=(--host "${RESTIC_HOST}")
+fi
+if [[ -n ${RESTIC_TAGS} ]]; then
+    SNAPSHOT_FILTER+=(--tag "${RESTIC_TAGS}")
+fi
+# The Secret's RESTIC_REPOSITORY may be a base location that is shared by many
+# sources, with the controller providing the path within it
+if [[ -n ${REPOSITORY_PATH} ]]; then
+    export RESTIC_REPOSITORY="${RESTIC_REPOSITORY%/}/${REPOSITORY_PATH}"
+fi
+# The credentials for a REST server are kept out of the repository URL in the
+# Secret, so add them to it here
+if [[ -n ${RESTIC_REST_USERNAME} && ${RESTIC_REPOSITORY} == rest:http* ]]; then
+    export RESTIC_REPOSITORY="${RESTIC_REPOSITORY/:\/\//://${RESTIC_REST_USERNAME}:${RESTIC_REST_PASSWORD}@}"
+fi
+# Trust a custom CA bundle when connecting to the repository
+RESTIC_OPTIONS=()
+if [[ -n ${CUSTOM_CA} ]]; then
+    RESTIC_OPTIONS+=(--cacert "${CUSTOM_CA}")
+fi
+function restic {
+    command restic "${RESTIC_OPTIONS[@]}" "$@"
+}
+# Location of the backup filters
+FILTERS_DIR="${FILTERS_DIR:-/filters}"
 # Make restic output progress reports every 10s
 export RESTIC_PROGRESS_FPS=0.1
 
@@ -26,6 +54,53 @@ function check_var_defined {
     fi
 }
 
+# Set an annotation on this mover's Job
+# annotate_job "scribe.backube/progress" '{"phase":"backup"}'
+function annotate_job {
+    if [[ -z ${JOB_NAME} || -z ${JOB_NAMESPACE} ]]; then
+        return 0
+    fi
+    local sa_dir="/var/run/secrets/kubernetes.io/serviceaccount"
+    local value="${2//\"/\\\"}"
+    curl --silent --output /dev/null --max-time 5 \
+        --cacert "${sa_dir}/ca.crt" \
+        --header "Authorization: Bearer $(<"${sa_dir}/token")" \
+        --header "Content-Type: application/merge-patch+json" \
+        --request PATCH \
+        --data "{\"metadata\":{\"annotations\":{\"$1\":\"${value}\"}}}" \
+        "https://${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}/apis/batch/v1/namespaces/${JOB_NAMESPACE}/jobs/${JOB_NAME}" \
+        || true
+}
+
+# Publish a progress report as an annotation on this mover's Job
+# report_progress '{"phase":"backup","bytesDone":1234}'
+function report_progress {
+    annotate_job "scribe.backube/progress" "$1"
+}
+
+# Extract a numeric field from a line of restic's JSON output
+# json_number "$line" "field_name"
+function json_number {
+    if [[ $1 =~ \"$2\":([0-9.eE+-]+) ]]; then
+        echo "${BASH_REMATCH[1]}"
+    fi
+}
+
+# Extract a string field from a line of restic's JSON output
+# json_string "$line" "field_name"
+function json_string {
+    if [[ $1 =~ \"$2\":\"([^\"]*)\" ]]; then
+        echo "${BASH_REMATCH[1]}"
+    fi
+}
+
+# Record the result of the operations. It is published as the container's
+# termination message once all operations have succeeded.
+# write_result '{"snapshotID":"abc123"}'
+function write_result {
+    echo "$1" > /tmp/sync_result
+}
+
 function check_contents {
     echo "== Checking directory for content ==="
     DIR_CONTENTS="$(ls -A "${DATA_DIR}")"
@@ -52,32 +127,437 @@ function ensure_initialized {
     rm -f "$outfile"
 }
 
+# Switch to RESTIC_NEW_PASSWORD if a key rotation (possibly by another mover
+# sharing the repository) has already replaced RESTIC_PASSWORD
+function select_password {
+    if [[ -z ${RESTIC_NEW_PASSWORD} ]] || restic cat config > /dev/null 2>&1; then
+        return 0
+    fi
+    if RESTIC_PASSWORD="${RESTIC_NEW_PASSWORD}" restic cat config > /dev/null 2>&1; then
+        echo "Using the new repository password"
+        export RESTIC_PASSWORD="${RESTIC_NEW_PASSWORD}"
+    fi
+}
+
+# Remove the locks left behind by movers that did not exit cleanly, provided
+# that all of them are older than STALE_LOCK_AGE (in seconds). Live movers
+# refresh their locks, so only stale locks can become that old. The locks that
+# were found are published as an annotation on this mover's Job.
+function handle_locks {
+    local ids
+    if ! ids="$(restic list locks --no-lock 2>/dev/null)"; then
+        # e.g., the repository hasn't been initialized yet
+        return 0
+    fi
+    local count=0 oldest="" oldest_s=0 newest_s=0 id lock lock_time lock_s
+    for id in $ids; do
+        if ! lock="$(restic cat lock --no-lock "$id" 2>/dev/null | tr -d '[:space:]')"; then
+            continue
+        fi
+        lock_time="$(json_string "$lock" time)"
+        lock_s="$(date -d "$lock_time" +%s)"
+        count=$((count + 1))
+        if [[ -z $oldest ]] || (( lock_s < oldest_s )); then
+            oldest="$lock_time"
+            oldest_s="$lock_s"
+        fi
+        if (( lock_s > newest_s )); then
+            newest_s="$lock_s"
+        fi
+    done
+    local removed=false
+    if (( count > 0 )); then
+        echo "Found ${count} repository lock(s), the oldest created at ${oldest}"
+        if [[ -n ${STALE_LOCK_AGE} ]] && (( $(date +%s) - newest_s > STALE_LOCK_AGE )); then
+            echo "Removing stale locks"
+            restic unlock --remove-all
+            removed=true
+        fi
+    fi
+    annotate_job "scribe.backube/restic-locks" \
+        "$(printf '{"count":%d,"oldest":"%s","removed":%s}' "$count" "$oldest" "$removed")"
+}
+
+# Print the ID of the repository key that matches RESTIC_PASSWORD
+function current_key {
+    local key
+    while read -r key; do
+        if [[ $key =~ \"current\":true ]]; then
+            json_string "$key" id
+        fi
+    done < <(restic key list --json | sed 's/},{/}\n{/g')
+}
+
+# Add a repository key for RESTIC_NEW_PASSWORD alongside the one for
+# RESTIC_PASSWORD. Other movers sharing the repository may still use the old
+# password, so its key is only removed later by retire-key.
+function do_rotate_key {
+    echo "=== Starting key rotation ==="
+    report_progress '{"phase":"rotate-key"}'
+    ensure_initialized
+    local old_key=""
+    if [[ ${RESTIC_PASSWORD} != "${RESTIC_NEW_PASSWORD}" ]]; then
+        old_key="$(current_key)"
+        local password_file
+        password_file="$(mktemp -q)"
+        printf '%s' "${RESTIC_NEW_PASSWORD}" > "$password_file"
+        restic key add --new-password-file "$password_file"
+        rm -f "$password_file"
+        export RESTIC_PASSWORD="${RESTIC_NEW_PASSWORD}"
+    fi
+    local new_key
+    new_key="$(current_key)"
+    if [[ -z ${new_key} ]]; then
+        error 5 "unable to open the repository with the new password"
+    fi
+    if [[ ${old_key} == "${new_key}" ]]; then
+        old_key=""
+    fi
+    annotate_job "scribe.backube/restic-key" \
+        "$(printf '{"keyID":"%s","oldKeyID":"%s"}' "${new_key}" "${old_key}")"
+}
+
+# Remove the repository key RETIRE_KEY that was replaced by a key rotation
+function do_retire_key {
+    echo "=== Retiring key ${RETIRE_KEY} ==="
+    check_var_defined RETIRE_KEY
+    if [[ $(current_key) == "${RETIRE_KEY}" ]]; then
+        error 5 "refusing to remove key ${RETIRE_KEY} since it is still in use"
+    fi
+    # Another mover sharing the repository may have removed it already
+    if restic key list --json | grep -q "\"id\":\"${RETIRE_KEY}\""; then
+        restic key remove "${RETIRE_KEY}"
+    fi
+}
+
+# Print the backup options for the filters (one per line) that are in
+# FILTERS_DIR. Anchored patterns are relative to the root of the volume.
+function filter_options {
+    if [[ -s ${FILTERS_DIR}/exclude ]]; then
+        sed "s|^/|${DATA_DIR}/|" "${FILTERS_DIR}/exclude" > /tmp/exclude
+        echo "--exclude-file=/tmp/exclude"
+    fi
+    if [[ $(<"${FILTERS_DIR}/exclude-caches") == "true" ]]; then
+        echo "--exclude-caches"
+    fi
+    local name
+    while read -r name; do
+        if [[ -n $name ]]; then
+            echo "--exclude-if-present=${name}"
+        fi
+    done < "${FILTERS_DIR}/exclude-if-present"
+    if [[ -s ${FILTERS_DIR}/include ]]; then
+        sed 's|^/*||' "${FILTERS_DIR}/include" > /tmp/include
+        echo "--files-from=/tmp/include"
+    else
+        echo "."
+    fi
+}
+
+# Turn restic's JSON backup output into progress reports and the sync result.
+# It provides status lines (at RESTIC_PROGRESS_FPS), followed by a summary
+# that we log.
+function parse_backup_output {
+    local line
+    while read -r line; do
+        if [[ $line =~ \"message_type\":\"status\" ]]; then
+            local percent
+            percent="$(json_number "$line" percent_done)"
+            report_progress "$(printf '{"phase":"backup","bytesDone":%d,"bytesTotal":%d,"filesDone":%d,"filesTotal":%d,"percentDone":%d}' \
+                "$(json_number "$line" bytes_done)" \
+                "$(json_number "$line" total_bytes)" \
+                "$(json_number "$line" files_done)" \
+                "$(json_number "$line" total_files)" \
+                "$(awk "BEGIN {print int(${percent:-0} * 100)}")")"
+        elif [[ $line =~ \"message_type\":\"summary\" ]]; then
+            echo "$line"
+            local files_new files_changed
+            files_new="$(json_number "$line" files_new)"
+            files_changed="$(json_number "$line" files_changed)"
+            write_result "$(printf '{"bytesTransferred":%d,"bytesTotal":%d,"filesChanged":%d,"filesTotal":%d,"snapshotID":"%s","details":{"filesNew":"%d","filesUnmodified":"%d"}}' \
+                "$(json_number "$line" data_added)" \
+                "$(json_number "$line" total_bytes_processed)" \
+                "$(( ${files_new:-0} + ${files_changed:-0} ))" \
+                "$(json_number "$line" total_files_processed)" \
+                "$(json_string "$line" snapshot_id)" \
+                "${files_new:-0}" \
+                "$(json_number "$line" files_unmodified)")"
+        else
+            echo "$line"
+        fi
+    done
+}
+
 function do_backup {
     echo "=== Starting backup ==="
     pushd "${DATA_DIR}"
-    restic backup --host "${RESTIC_HOST}" .
+    local options=(".")
+    if [[ -d ${FILTERS_DIR} ]]; then
+        mapfile -t options < <(filter_options)
+    fi
+    restic backup --json "${SNAPSHOT_FILTER[@]}" "${options[@]}" | parse_backup_output
     popd
 }
 
 function do_forget {
     echo "=== Starting forget ==="
-    if [[ -n ${FORGET_OPTIONS} ]]; then
-        #shellcheck disable=SC2086
-        restic forget --host "${RESTIC_HOST}" ${FORGET_OPTIONS}
+    report_progress '{"phase":"forget"}'
+    if [[ -z ${FORGET_OPTIONS} ]]; then
+        return 0
+    fi
+    local filter=("${SNAPSHOT_FILTER[@]}")
+    # Older releases recorded every snapshot with the host name "scribe".
+    # Group those with this source's snapshots so that the retain policy
+    # removes them as they are superseded.
+    if [[ -n ${RESTIC_HOST} && ${RESTIC_HOST} != "scribe" && -z ${RESTIC_TAGS} ]]; then
+        filter+=(--host scribe --group-by paths)
     fi
+    #shellcheck disable=SC2086
+    restic forget "${filter[@]}" ${FORGET_OPTIONS}
+}
+
+# Publish the repository's totals and its most recent snapshots (at most
+# SNAPSHOT_REPORT_LIMIT) as an annotation on this mover's Job
+function report_snapshots {
+    local snapshots
+    if ! snapshots="$(restic snapshots --json "${SNAPSHOT_FILTER[@]}" | sed 's/},{/}\n{/g')"; then
+        echo "Unable to list snapshots"
+        return 0
+    fi
+    local count=0 entries=() snapshot tags
+    while read -r snapshot; do
+        if [[ -z "$(json_string "$snapshot" id)" ]]; then
+            continue
+        fi
+        count=$((count + 1))
+        tags=""
+        if [[ $snapshot =~ \"tags\":\[([^]]*)\] ]]; then
+            tags="${BASH_REMATCH[1]}"
+        fi
+        entries+=("$(printf '{"id":"%s","time":"%s","tags":[%s]}' \
+            "$(json_string "$snapshot" id)" "$(json_string "$snapshot" time)" "$tags")")
+    done < <(echo "$snapshots")
+    # restic lists snapshots oldest first
+    local limit="${SNAPSHOT_REPORT_LIMIT:-20}"
+    local start=$(( count > limit ? count - limit : 0 ))
+    local list
+    list="$(IFS=,; echo "${entries[*]:$start}")"
+    # The size only covers this source's snapshots, like the count. Other
+    # movers may keep using the repository while the stats are gathered.
+    local size
+    size="$(json_number "$(restic stats --json --no-lock --mode raw-data "${SNAPSHOT_FILTER[@]}")" total_size)"
+    annotate_job "scribe.backube/restic-repository" \
+        "$(printf '{"snapshotCount":%d,"totalSize":%d,"snapshots":[%s]}' \
+        "$count" "${size:-0}" "$list")"
 }
 
 function do_prune {
     echo "=== Starting prune ==="
+    report_progress '{"phase":"prune"}'
     restic prune
 }
 
+# Verify the integrity of the repository. Errors are published as an
+# annotation on this mover's Job rather than failing the backup.
+function do_check {
+    echo "=== Starting check ==="
+    report_progress '{"phase":"check"}'
+    local options=""
+    if [[ -n ${READ_DATA_SUBSET} ]]; then
+        options="--read-data-subset=${READ_DATA_SUBSET}"
+    fi
+    local output rc=0
+    #shellcheck disable=SC2086
+    output="$(restic check ${options} 2>&1)" || rc=$?
+    echo "$output"
+    if [[ $rc -eq 0 ]]; then
+        annotate_job "scribe.backube/restic-check" '{"healthy":true}'
+        return 0
+    fi
+    # Summarize the errors using only characters that are safe within JSON
+    local message
+    message="$(echo "$output" | tail -n 5 | tr '\n' ' ' | tr -cd '[:alnum:] .,:;/()%=_-' | cut -c1-1024)"
+    echo "Repository check found errors"
+    annotate_job "scribe.backube/restic-check" \
+        "$(printf '{"healthy":false,"message":"%s"}' "$message")"
+}
+
+# Print the description of the snapshot to restore: the one named by
+# SNAPSHOT_ID, otherwise the newest one taken at or before RESTORE_AS_OF (or
+# the newest one if that isn't set either)
+function select_snapshot {
+    local snapshots
+    if [[ -n ${SNAPSHOT_ID} ]]; then
+        snapshots="$(restic snapshots --json "${SNAPSHOT_ID}")"
+    else
+        snapshots="$(restic snapshots --json "${SNAPSHOT_FILTER[@]}")"
+    fi
+    local limit=""
+    if [[ -n ${RESTORE_AS_OF} ]]; then
+        limit="$(date -d "${RESTORE_AS_OF}" +%s)"
+    fi
+    local selected="" selected_time=0
+    local snapshot snapshot_time
+    # The list is a JSON array of flat objects, so split it into one per line
+    while read -r snapshot; do
+        if [[ -z "$(json_string "$snapshot" id)" ]]; then
+            continue
+        fi
+        snapshot_time="$(date -d "$(json_string "$snapshot" time)" +%s)"
+        if [[ -n $limit ]] && (( snapshot_time > limit )); then
+            continue
+        fi
+        if [[ -z $selected ]] || (( snapshot_time >= selected_time )); then
+            selected="$snapshot"
+            selected_time="$snapshot_time"
+        fi
+    done < <(echo "$snapshots" | sed 's/},{/}\n{/g')
+    echo "$selected"
+}
+
+# Without a host name, a restore from a repository that is shared by several
+# sources would pick whichever of them backed up last, so refuse to guess
+function check_single_host {
+    if [[ -n ${RESTIC_HOST} || -n ${SNAPSHOT_ID} ]]; then
+        return
+    fi
+    local snapshots hosts
+    snapshots="$(restic snapshots --json "${SNAPSHOT_FILTER[@]}")"
+    hosts="$(while read -r snapshot; do
+        json_string "$snapshot" hostname
+    done < <(echo "$snapshots" | sed 's/},{/}\n{/g') | sort -u | tr '\n' ' ')"
+    if [[ $(echo "$hosts" | wc -w) -gt 1 ]]; then
+        error 4 "the repository holds snapshots of several hosts (${hosts% }), set hostname to select one"
+    fi
+}
+
+# Remove the contents of the data volume, except for the file system's
+# lost+found directory
+function empty_data_dir {
+    if [[ ${DATA_DIR} != /?* ]]; then
+        error 5 "refusing to empty ${DATA_DIR}"
+    fi
+    echo "Removing the existing contents of ${DATA_DIR}"
+    find "${DATA_DIR}" -mindepth 1 -maxdepth 1 ! -name lost+found -exec rm -rf {} +
+}
+
 function do_restore {
     echo "=== Starting restore ==="
+    report_progress '{"phase":"restore"}'
     pushd "${DATA_DIR}"
-    restic restore -t . --host "${RESTIC_HOST}" latest
+    check_single_host
+    local snapshot snapshot_id
+    snapshot="$(select_snapshot)"
+    snapshot_id="$(json_string "$snapshot" id)"
+    if [[ -z ${snapshot_id} ]]; then
+        error 4 "no matching snapshot found"
+    fi
+    # restic rewrites every file of the snapshot during a restore, so a mirror
+    # is produced by starting from an empty volume
+    if [[ ${RESTORE_MODE} == "Mirror" ]]; then
+        empty_data_dir
+    fi
+    echo "Restoring snapshot ${snapshot_id} from $(json_string "$snapshot" time)"
+    restic restore -t . "${snapshot_id}"
+    write_result "$(printf '{"snapshotID":"%s","details":{"snapshotTime":"%s"}}' \
+        "${snapshot_id}" "$(json_string "$snapshot" time)")"
     popd
 }
+
+# Create the FIFO through which the stream command's data passes, and make
+# sure that the stream container doesn't wait for it forever if we exit early
+function open_stream {
+    mkfifo -m 0666 "${STREAM_DIR}/pipe"
+    trap close_stream EXIT
+}
+
+# Tell the stream container that we're finished, and release it if it is
+# still waiting to open the FIFO
+function close_stream {
+    touch "${STREAM_DIR}/done"
+    exec 9<> "${STREAM_DIR}/pipe"
+    exec 9>&-
+}
+
+# Print the exit status of the stream command, once it has been written to
+# the shared volume
+function stream_status {
+    local i
+    for (( i = 0; i < 60; i++ )); do
+        if [[ -s ${STREAM_DIR}/status ]]; then
+            cat "${STREAM_DIR}/status"
+            return 0
+        fi
+        sleep 1
+    done
+    echo "unknown"
+}
+
+# Back up the output of the stream command as STREAM_FILENAME. The data is
+# passed to restic through a FIFO of our own so that the backup can be
+# interrupted, before any snapshot is saved, if the command fails.
+function do_backup_stream {
+    echo "=== Starting stream backup ==="
+    check_var_defined STREAM_DIR
+    check_var_defined STREAM_FILENAME
+    open_stream
+    local feed=/tmp/stream-feed
+    mkfifo "$feed"
+    ( command restic "${RESTIC_OPTIONS[@]}" backup --json "${SNAPSHOT_FILTER[@]}" \
+        --stdin --stdin-filename "${STREAM_FILENAME}" < "$feed" &
+      echo $! > /tmp/restic.pid
+      wait $! ) | parse_backup_output &
+    local backup=$!
+    exec 5> "$feed"
+    cat "${STREAM_DIR}/pipe" >&5 || true
+    local rc
+    rc="$(stream_status)"
+    if [[ $rc != 0 ]]; then
+        kill -INT "$(</tmp/restic.pid)" || true
+        exec 5>&-
+        wait "$backup" || true
+        error 6 "stream command failed with exit status ${rc}"
+    fi
+    exec 5>&-
+    wait "$backup"
+}
+
+# Restore the STREAM_FILENAME of the selected snapshot, either to the stream
+# command (via STREAM_DIR) or to a file of the data volume
+function do_restore_stream {
+    echo "=== Starting stream restore ==="
+    report_progress '{"phase":"restore"}'
+    check_var_defined STREAM_FILENAME
+    check_single_host
+    local snapshot snapshot_id
+    snapshot="$(select_snapshot)"
+    snapshot_id="$(json_string "$snapshot" id)"
+    if [[ -z ${snapshot_id} ]]; then
+        error 4 "no matching snapshot found"
+    fi
+    echo "Restoring ${STREAM_FILENAME} of snapshot ${snapshot_id} from $(json_string "$snapshot" time)"
+    if [[ -n ${STREAM_DIR} ]]; then
+        open_stream
+        local dump_rc=0
+        restic dump "${snapshot_id}" "/${STREAM_FILENAME}" > "${STREAM_DIR}/pipe" || dump_rc=$?
+        local rc
+        rc="$(stream_status)"
+        if [[ $rc != 0 ]]; then
+            error 6 "stream command failed with exit status ${rc}"
+        fi
+        if [[ $dump_rc != 0 ]]; then
+            error 6 "unable to restore ${STREAM_FILENAME}"
+        fi
+    else
+        # Replace the file only once it has been restored completely
+        local partial="${DATA_DIR}/.${STREAM_FILENAME}.partial"
+        restic dump "${snapshot_id}" "/${STREAM_FILENAME}" > "$partial"
+        mv -f "$partial" "${DATA_DIR}/${STREAM_FILENAME}"
+    fi
+    write_result "$(printf '{"snapshotID":"%s","details":{"snapshotTime":"%s"}}' \
+        "${snapshot_id}" "$(json_string "$snapshot" time)")"
+}
+
 echo "Testing mandatory env variables"
 # Check the mandatory env variables
 for var in RESTIC_CACHE_DIR \
@@ -87,25 +567,50 @@ for var in RESTIC_CACHE_DIR \
            ; do
     check_var_defined $var
 done
+select_password
+handle_locks
 
 for op in "$@"; do
     case $op in
+        "rotate-key")
+            do_rotate_key
+            ;;
+        "retire-key")
+            do_retire_key
+            ;;
         "backup")
             check_contents
             ensure_initialized
             do_backup
             do_forget
+            report_snapshots
+            ;;
+        "backup-stream")
+            ensure_initialized
+            do_backup_stream
+            do_forget
+            report_snapshots
             ;;
         "prune")
             do_prune
             ;;
+        "check")
+            do_check
+            ;;
         "restore")
             do_restore
             ;;
+        "restore-stream")
+            do_restore_stream
+            ;;
         *)
             error 2 "unknown operation: $op"
             ;;
     esac
 done
 sync
+# Publish the result via the termination message for the controller
+if [[ -e /tmp/sync_result ]]; then
+    cp /tmp/sync_result /dev/termination-log || true
+fi
 echo "=== Done ==="
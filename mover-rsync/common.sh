@@ -0,0 +1,233 @@
+#! /bin/bash
+
+# Functions shared by source.sh & destination.sh. Whichever side has been
+# given the address of the other acts as the ssh client and initiates the
+# transfer. The other side acts as the ssh server, accepting a single
+# connection from its peer.
+
+# Publish a progress report as an annotation on this mover's Job
+# report_progress '{"phase":"transfer","bytesDone":1234}'
+function report_progress {
+    if [[ -z ${JOB_NAME} || -z ${JOB_NAMESPACE} ]]; then
+        return 0
+    fi
+    local sa_dir="/var/run/secrets/kubernetes.io/serviceaccount"
+    local value="${1//\"/\\\"}"
+    curl --silent --output /dev/null --max-time 5 \
+        --cacert "${sa_dir}/ca.crt" \
+        --header "Authorization: Bearer $(<"${sa_dir}/token")" \
+        --header "Content-Type: application/merge-patch+json" \
+        --request PATCH \
+        --data "{\"metadata\":{\"annotations\":{\"scribe.backube/progress\":\"${value}\"}}}" \
+        "https://${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}/apis/batch/v1/namespaces/${JOB_NAMESPACE}/jobs/${JOB_NAME}" \
+        || true
+}
+
+# rsync_number converts a number from rsync's human-readable output (e.g.,
+# "1,234" or "1.23M") into an integer
+# Usage: rsync_number <number> [unit]
+function rsync_number {
+    awk -v n="${1//,/}" -v unit="$2" \
+        'BEGIN {printf "%d", n * 1000 ^ (unit == "" ? 0 : index("KMGTP", unit))}'
+}
+
+# report_rsync_progress turns rsync's --info=progress2 output into progress
+# reports (at most one every 10s), passing everything else through to the log.
+# The transfer statistics from --info=stats2 are saved in /tmp/sync_result.
+# Usage: rsync ... | report_rsync_progress
+function report_rsync_progress {
+    local last_report=-10
+    local line
+    local files_total=0 files_changed=0 bytes_total=0 bytes_transferred=0
+    local files_created=0 files_deleted=0
+    # Progress updates are separated by carriage returns, not newlines
+    tr '\r' '\n' | {
+        while IFS= read -r line; do
+            # e.g.: "  1.23G  45%  10.00MB/s  0:01:02 (xfr#50, to-chk=25/100)"
+            if [[ $line =~ ^\ *([0-9.,]+)([KMGTP]?)\ +([0-9]+)%.*-chk=([0-9]+)/([0-9]+)\) ]]; then
+                if (( SECONDS - last_report < 10 )); then
+                    continue
+                fi
+                last_report=$SECONDS
+                report_progress "$(printf '{"phase":"transfer","bytesDone":%d,"filesDone":%d,"filesTotal":%d,"percentDone":%d}' \
+                    "$(rsync_number "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}")" \
+                    "$(( BASH_REMATCH[5] - BASH_REMATCH[4] ))" \
+                    "${BASH_REMATCH[5]}" \
+                    "${BASH_REMATCH[3]}")"
+                continue
+            fi
+            if [[ $line =~ ^Number\ of\ files:\ ([0-9.,]+)([KMGTP]?) ]]; then
+                files_total="$(rsync_number "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}")"
+            elif [[ $line =~ ^Number\ of\ created\ files:\ ([0-9.,]+)([KMGTP]?) ]]; then
+                files_created="$(rsync_number "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}")"
+            elif [[ $line =~ ^Number\ of\ deleted\ files:\ ([0-9.,]+)([KMGTP]?) ]]; then
+                files_deleted="$(rsync_number "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}")"
+            elif [[ $line =~ ^Number\ of\ regular\ files\ transferred:\ ([0-9.,]+)([KMGTP]?) ]]; then
+                files_changed="$(rsync_number "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}")"
+            elif [[ $line =~ ^Total\ file\ size:\ ([0-9.,]+)([KMGTP]?) ]]; then
+                bytes_total="$(rsync_number "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}")"
+            elif [[ $line =~ ^Total\ transferred\ file\ size:\ ([0-9.,]+)([KMGTP]?) ]]; then
+                bytes_transferred="$(rsync_number "${BASH_REMATCH[1]}" "${BASH_REMATCH[2]}")"
+            fi
+            if [[ -n $line ]]; then
+                echo "$line"
+            fi
+        done
+        printf '{"bytesTransferred":%d,"bytesTotal":%d,"filesChanged":%d,"filesTotal":%d,"details":{"filesCreated":"%d","filesDeleted":"%d"}}\n' \
+            "$bytes_transferred" "$bytes_total" "$files_changed" "$files_total" \
+            "$files_created" "$files_deleted" > /tmp/sync_result
+    }
+}
+
+# run_client connects to the remote ssh server and runs rsync, then notifies
+# the server of the result.
+# Usage: run_client <identity> <remote host key> <address> <port> <user> <rsync src> <rsync dst>
+function run_client {
+    local identity="$1"
+    local host_key="$2"
+    local address="$3"
+    local port="$4"
+    local user="$5"
+    local src="$6"
+    local dst="$7"
+
+    mkdir -p ~/.ssh/controlmasters
+    chmod 711 ~/.ssh
+
+    # Provide ssh host key to validate remote
+    echo "$address $(<"$host_key")" > ~/.ssh/known_hosts
+
+    cat - <<SSHCONFIG > ~/.ssh/config
+Host *
+  # Wait max 30s to establish connection
+  ConnectTimeout 30
+  # Control persist to speed 2nd ssh connection
+  ControlMaster auto
+  ControlPath ~/.ssh/controlmasters/%C
+  ControlPersist 5
+  # Disables warning when IP is added to known_hosts
+  CheckHostIP no
+  # Use the identity provided via attached Secret
+  IdentityFile ${identity}
+  Port ${port}
+  # Enable protocol-level keepalive to detect connection failure
+  ServerAliveCountMax 4
+  ServerAliveInterval 30
+  # We know the key of the server, so be strict
+  StrictHostKeyChecking yes
+  # Using protocol-level, so we don't need TCP-level
+  TCPKeepAlive no
+SSHCONFIG
+
+    local MAX_RETRIES=5
+    local RETRY=0
+    local DELAY=2
+    local FACTOR=2
+    local rc=1
+    echo "Syncing data from ${src} to ${dst} via ${address}:${port} ..."
+    local START_TIME=$SECONDS
+    # Avoids exiting on rsync failure
+    set +e
+    while [[ ${rc} -ne 0 && ${RETRY} -lt ${MAX_RETRIES} ]]
+    do
+        RETRY=$((RETRY + 1))
+        # Scanning the whole tree up front (no-inc-recursive) gives accurate
+        # totals for the progress reports
+        rsync -aAhHSxz --delete --itemize-changes --info=stats2,misc2,progress2 \
+            --no-inc-recursive "$src" "$dst" | report_rsync_progress
+        rc=${PIPESTATUS[0]}
+        if [[ ${rc} -ne 0 ]]; then
+            echo "Syncronization failed. Retrying in ${DELAY} seconds. Retry ${RETRY}/${MAX_RETRIES}."
+            sleep ${DELAY}
+            DELAY=$((DELAY * FACTOR ))
+        fi
+    done
+    set -e
+    echo "Rsync completed in $(( SECONDS - START_TIME ))s"
+    sync
+    if [[ $rc -eq 0 ]]; then
+        echo "Synchronization completed successfully. Notifying remote..."
+        # Both sides publish the result via their termination message
+        local result
+        result="$(</tmp/sync_result)"
+        echo "$result" > /dev/termination-log || true
+        ssh "${user}@${address}" shutdown 0 "'${result}'"
+    else
+        echo "Synchronization failed. rsync returned: $rc"
+        exit $rc
+    fi
+}
+
+# run_server runs sshd, allowing the peer's key to run rsync within a single
+# directory of the data volume, then exits with the result reported by the
+# peer.
+# Usage: run_server <host key> <peer public key> <user> <path> <rrsync option>
+function run_server {
+    local host_key="$1"
+    local peer_key="$2"
+    local user="$3"
+    local path="$4"
+    # -ro or -wo to limit the direction of the transfer
+    local rrsync_opt="$5"
+
+    if [[ "$path" =~ (^|/)\.\.(/|$) ]]; then
+        echo "Path must not contain '..': $path"
+        exit 1
+    fi
+    local data_dir="/data/${path#/}"
+    data_dir="${data_dir%/}"
+
+    if [[ "$user" != "root" ]]; then
+        # Create the account for the incoming connection. It needs an unlocked
+        # password field for sshd to allow key-based logins.
+        if ! id -u "$user" > /dev/null 2>&1; then
+            useradd --create-home --password '*' --shell /bin/bash "$user"
+        fi
+    fi
+    if [[ "$rrsync_opt" != "-ro" ]]; then
+        mkdir -p "$data_dir"
+        if [[ "$user" != "root" ]]; then
+            # rsync runs as the user, so it must own the data it replicates into
+            chown -R "$user": "$data_dir"
+        fi
+    fi
+    local home_dir
+    home_dir="$(getent passwd "$user" | cut -d: -f6)"
+
+    # Allow peer's key to access, but restrict what it can do.
+    mkdir -p "$home_dir/.ssh"
+    chmod 700 "$home_dir/.ssh"
+    echo "command=\"/server-command.sh '$data_dir' $rrsync_opt\",restrict $(<"$peer_key")" > "$home_dir/.ssh/authorized_keys"
+    chown -R "$user": "$home_dir/.ssh"
+
+    # Wait for incoming rsync transfer
+    echo "Waiting for connection..."
+    rm -f /var/run/nologin
+    /usr/sbin/sshd -D -e -q -h "$host_key" &
+    local sshd_pid=$!
+
+    # The peer may be connected as an unprivileged user, so it can't stop sshd
+    # directly. Instead, it leaves the result in /tmp/exit_code, and we shut
+    # down sshd once that appears.
+    while [[ ! -e /tmp/exit_code ]] && kill -0 "$sshd_pid" 2> /dev/null; do
+        sleep 1
+    done
+    kill -SIGTERM "$sshd_pid" 2> /dev/null || true
+    wait "$sshd_pid" || true
+
+    # When sshd exits, need to return the proper exit code from the rsync operation
+    local CODE=255
+    if [[ -e /tmp/exit_code ]]; then
+        local CODE_IN
+        CODE_IN="$(</tmp/exit_code)"
+        if [[ $CODE_IN =~ ^[0-9]+$ ]]; then
+            CODE="$CODE_IN"
+        fi
+    fi
+    if [[ $CODE -eq 0 && -e /tmp/sync_result ]]; then
+        cp /tmp/sync_result /dev/termination-log || true
+    fi
+    sync
+    echo "Exiting... Exit code: $CODE"
+    exit "$CODE"
+}
@@ -4,24 +4,19 @@ set -e -o pipefail
 
 echo "Scribe rsync container version: ${version:-unknown}"
 
-# Allow source's key to access, but restrict what it can do.
-mkdir -p ~/.ssh
-chmod 700 ~/.ssh
-echo "command=\"/destination-command.sh\",restrict $(</keys/source.pub)" > ~/.ssh/authorized_keys
+# shellcheck source=common.sh
+source /common.sh
 
-# Wait for incoming rsync transfer
-echo "Waiting for connection..."
-rm -f /var/run/nologin
-/usr/sbin/sshd -D -e -q
-
-# When sshd exits, need to return the proper exit code from the rsync operation
-CODE=255
-if [[ -e /tmp/exit_code ]]; then
-    CODE_IN="$(</tmp/exit_code)"
-    if [[ $CODE_IN =~ ^[0-9]+$ ]]; then
-        CODE="$CODE_IN"
-    fi
+if [[ -n "$SOURCE_ADDRESS" ]]; then
+    # Pull: connect to the source and fetch its data
+    SOURCE_PORT="${SOURCE_PORT:-22}"
+    SOURCE_USER="${SOURCE_USER:-root}"
+    SOURCE_PATH="${SOURCE_PATH:-/}"
+    run_client /keys/destination /keys/source.pub \
+        "$SOURCE_ADDRESS" "$SOURCE_PORT" "$SOURCE_USER" \
+        "${SOURCE_USER}@${SOURCE_ADDRESS}:${SOURCE_PATH%/}/" /data/
+else
+    # Push: wait for the source to connect and write its data
+    run_server /keys/destination /keys/source.pub \
+        "${DESTINATION_USER:-root}" "${DESTINATION_PATH:-/}" ""
 fi
-sync
-echo "Exiting... Exit code: $CODE"
-exit "$CODE"
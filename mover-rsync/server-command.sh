@@ -0,0 +1,44 @@
+#! /bin/bash
+
+set -e -o pipefail
+
+# Set by run_server in common.sh via the authorized_keys entry
+DATA_DIR="$1"
+RRSYNC_OPT="$2"
+
+function do_shutdown {
+    rc="$1"
+    result="$2"
+
+    echo "Initiating shutdown. Exit code: $rc"
+
+    # The peer's result of the transfer is published by the main process
+    if [[ -n "$result" ]]; then
+        echo "$result" > /tmp/sync_result
+    fi
+
+    # /tmp/exit_code is watched by the main process. Once it appears, sshd is
+    # terminated and the code is used as the return code for the container.
+    echo "$rc" >> /tmp/exit_code
+}
+
+function do_rsync {
+    # rsync changes are restricted to the requested directory of the container
+    # (the /data directory by default)
+    # shellcheck disable=SC2086
+    LANG=C rrsync $RRSYNC_OPT "${DATA_DIR:-/data}"
+}
+
+#-- These are the only commands allowed to be executed by the peer:
+# Peer can initiate an rsync
+if [[ "$SSH_ORIGINAL_COMMAND" =~ ^rsync( ) ]]; then
+    do_rsync
+# Peer can tell us to shutdown & pass a numeric result code, optionally
+# followed by the JSON result of the transfer
+elif [[ "$SSH_ORIGINAL_COMMAND" =~ ^shutdown( )+([0-9]+)( +\'(\{[-a-zA-Z0-9_\":,.{}]*\})\')?$ ]]; then
+    do_shutdown "${BASH_REMATCH[2]}" "${BASH_REMATCH[4]}"
+# Everything else is an error
+else
+    echo "Invalid command: $SSH_ORIGINAL_COMMAND"
+    exit 1
+fi
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package provider
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers/mover"
+)
+
+// providerBuilder adapts a provider to the mover.Builder interface so that
+// Scribe's reconcilers can drive it like any other data mover.
+type providerBuilder struct {
+	options Options
+}
+
+var _ mover.Builder = &providerBuilder{}
+
+func (pb *providerBuilder) FromSource(client client.Client, logger logr.Logger,
+	source *scribev1alpha1.ReplicationSource) (mover.Mover, error) {
+	// Only build if the CR belongs to us
+	if pb.options.Source == nil || providerOf(source) != pb.options.Name {
+		return nil, nil
+	}
+	return &sourceMover{name: pb.options.Name, provider: pb.options.Source, source: source}, nil
+}
+
+func (pb *providerBuilder) FromDestination(client client.Client, logger logr.Logger,
+	destination *scribev1alpha1.ReplicationDestination) (mover.Mover, error) {
+	// Only build if the CR belongs to us
+	if pb.options.Destination == nil || providerOf(destination) != pb.options.Name {
+		return nil, nil
+	}
+	return &destinationMover{name: pb.options.Name, provider: pb.options.Destination,
+		destination: destination}, nil
+}
+
+// Providers interpret their own parameters, so there's nothing for Scribe to
+// validate.
+func (pb *providerBuilder) SourceCapabilities() mover.Capabilities      { return mover.Capabilities{} }
+func (pb *providerBuilder) DestinationCapabilities() mover.Capabilities { return mover.Capabilities{} }
+
+type sourceMover struct {
+	name     string
+	provider SourceProvider
+	source   *scribev1alpha1.ReplicationSource
+}
+
+var _ mover.Mover = &sourceMover{}
+
+func (m *sourceMover) Name() string { return m.name }
+
+func (m *sourceMover) Requirements() mover.Requirements { return mover.Requirements{} }
+
+func (m *sourceMover) Synchronize(ctx context.Context) (mover.Result, error) {
+	return m.provider.Synchronize(ctx, m.source)
+}
+
+func (m *sourceMover) Cleanup(ctx context.Context) (mover.Result, error) {
+	return m.provider.Cleanup(ctx, m.source)
+}
+
+type destinationMover struct {
+	name        string
+	provider    DestinationProvider
+	destination *scribev1alpha1.ReplicationDestination
+}
+
+var _ mover.Mover = &destinationMover{}
+
+func (m *destinationMover) Name() string { return m.name }
+
+func (m *destinationMover) Requirements() mover.Requirements { return mover.Requirements{} }
+
+func (m *destinationMover) Synchronize(ctx context.Context) (mover.Result, error) {
+	return m.provider.Synchronize(ctx, m.destination)
+}
+
+func (m *destinationMover) Cleanup(ctx context.Context) (mover.Result, error) {
+	return m.provider.Cleanup(ctx, m.destination)
+}
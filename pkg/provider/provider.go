@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package provider is a library for building external replication providers.
+//
+// ReplicationSources and ReplicationDestinations that use .spec.external are
+// ignored by Scribe's own controllers so that they can be serviced by a
+// separate operator. This package runs Scribe's ReplicationSource and
+// ReplicationDestination reconcilers on behalf of such an operator, limited to
+// the CRs that name its provider. They handle the triggers (schedule, manual,
+// and always), the Synchronizing and Reconciled conditions, the sync timing
+// fields of the status, and the metrics exactly as they do for the built-in
+// data movers. The provider only needs to implement the data transfer.
+//
+// Each call receives the CR being reconciled. Providers can read its spec
+// (including .spec.external.parameters and the trigger) and status, and they
+// may record their own information in .status.external and add their own
+// conditions. Changes to the status are saved at the end of the reconcile.
+// .status.lastSyncTime is set when Synchronize() returns a completed Result,
+// and on a ReplicationDestination, the Result's Image becomes
+// .status.latestImage.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/backube/scribe/controllers"
+	"github.com/backube/scribe/controllers/mover"
+)
+
+// SourceProvider replicates data from the ReplicationSources that name the
+// provider.
+type SourceProvider interface {
+	// Synchronize begins or continues a synchronization attempt. Attempts will
+	// continue at least until the Result indicates that the synchronization is
+	// complete. Must be idempotent.
+	Synchronize(ctx context.Context, source *scribev1alpha1.ReplicationSource) (Result, error)
+
+	// Cleanup begins or continues the post-synchronization cleanup of
+	// temporary resources. Must be idempotent.
+	Cleanup(ctx context.Context, source *scribev1alpha1.ReplicationSource) (Result, error)
+}
+
+// DestinationProvider replicates data into the ReplicationDestinations that
+// name the provider.
+type DestinationProvider interface {
+	// Synchronize begins or continues a synchronization attempt. Attempts will
+	// continue at least until the Result indicates that the synchronization is
+	// complete. The completed Result should contain the resulting Image. Must
+	// be idempotent.
+	Synchronize(ctx context.Context, destination *scribev1alpha1.ReplicationDestination) (Result, error)
+
+	// Cleanup begins or continues the post-synchronization cleanup of
+	// temporary resources. Must be idempotent.
+	Cleanup(ctx context.Context, destination *scribev1alpha1.ReplicationDestination) (Result, error)
+}
+
+// Result indicates the outcome of a synchronization attempt. It is the same
+// Result that Scribe's data movers return.
+type Result = mover.Result
+
+// Failure describes a failed attempt to synchronize. Reporting it via Failed()
+// lets the controller apply the CR's retryPolicy.
+type Failure = mover.Failure
+
+// Options describes an external replication provider.
+type Options struct {
+	// Name is the name of the provider. Only CRs with a matching
+	// .spec.external.provider will be reconciled.
+	Name string
+	// Source handles the provider's ReplicationSources. If nil,
+	// ReplicationSources are not reconciled.
+	Source SourceProvider
+	// Destination handles the provider's ReplicationDestinations. If nil,
+	// ReplicationDestinations are not reconciled.
+	Destination DestinationProvider
+}
+
+// SetupWithManager adds controllers for the provider's ReplicationSources and
+// ReplicationDestinations to the Manager. The Manager's scheme must include
+// the Scribe API types.
+func SetupWithManager(mgr ctrl.Manager, opts Options) error {
+	if opts.Name == "" {
+		return fmt.Errorf("a provider name is required")
+	}
+	catalog := []mover.Builder{&providerBuilder{options: opts}}
+	isOurs := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return providerOf(obj) == opts.Name
+	})
+
+	if opts.Source != nil {
+		r := &controllers.ReplicationSourceReconciler{
+			Client:  mgr.GetClient(),
+			Log:     ctrl.Log.WithName("provider").WithName("ReplicationSource"),
+			Scheme:  mgr.GetScheme(),
+			Catalog: catalog,
+		}
+		if err := ctrl.NewControllerManagedBy(mgr).
+			For(&scribev1alpha1.ReplicationSource{}, builder.WithPredicates(isOurs)).
+			Complete(r); err != nil {
+			return err
+		}
+	}
+
+	if opts.Destination != nil {
+		r := &controllers.ReplicationDestinationReconciler{
+			Client:  mgr.GetClient(),
+			Log:     ctrl.Log.WithName("provider").WithName("ReplicationDestination"),
+			Scheme:  mgr.GetScheme(),
+			Catalog: catalog,
+		}
+		if err := ctrl.NewControllerManagedBy(mgr).
+			For(&scribev1alpha1.ReplicationDestination{}, builder.WithPredicates(isOurs)).
+			Complete(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// providerOf returns the name of the external provider used by a CR, or "" if
+// it doesn't use one.
+func providerOf(obj client.Object) string {
+	switch cr := obj.(type) {
+	case *scribev1alpha1.ReplicationSource:
+		if cr.Spec.External != nil {
+			return cr.Spec.External.Provider
+		}
+	case *scribev1alpha1.ReplicationDestination:
+		if cr.Spec.External != nil {
+			return cr.Spec.External.Provider
+		}
+	}
+	return ""
+}
+
+// InProgress result indicates that the requested operation is still ongoing,
+// but it does not request an explicit requeueing.
+func InProgress() Result { return mover.InProgress() }
+
+// Failed indicates that an attempt of the requested operation has failed, and
+// it provides the cause to the controller. The controller decides whether and
+// when the operation is retried.
+func Failed(failure *Failure) Result { return mover.Failed(failure) }
+
+// RetryAfter indicates the operation is ongoing and requests explicit
+// requeueing after the provided duration.
+func RetryAfter(s time.Duration) Result { return mover.RetryAfter(s) }
+
+// Complete indicates that the operation has completed.
+func Complete() Result { return mover.Complete() }
+
+// CompleteWithImage indicates that the operation has completed, and it provides
+// the synchronized image to the controller.
+func CompleteWithImage(image *corev1.TypedLocalObjectReference) Result {
+	return mover.CompleteWithImage(image)
+}
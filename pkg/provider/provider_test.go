@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Scribe authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package provider
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+// testProvider completes each synchronization immediately, recording the
+// parameters it was given in the status.
+type testProvider struct {
+	mutex sync.Mutex
+	syncs map[types.NamespacedName]int
+}
+
+func (p *testProvider) Synchronize(ctx context.Context,
+	source *scribev1alpha1.ReplicationSource) (Result, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.syncs == nil {
+		p.syncs = map[types.NamespacedName]int{}
+	}
+	p.syncs[types.NamespacedName{Name: source.Name, Namespace: source.Namespace}]++
+	source.Status.External = map[string]string{
+		"target": source.Spec.External.Parameters["target"],
+	}
+	return Complete(), nil
+}
+
+func (p *testProvider) Cleanup(ctx context.Context,
+	source *scribev1alpha1.ReplicationSource) (Result, error) {
+	return Complete(), nil
+}
+
+func (p *testProvider) syncCount(rs *scribev1alpha1.ReplicationSource) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.syncs[types.NamespacedName{Name: rs.Name, Namespace: rs.Namespace}]
+}
+
+var _ = Describe("An external provider", func() {
+	var ctx = context.Background()
+	var namespace *corev1.Namespace
+	var rs *scribev1alpha1.ReplicationSource
+
+	BeforeEach(func() {
+		// Each test is run in its own namespace
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "scribe-test-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+		Expect(namespace.Name).NotTo(BeEmpty())
+
+		rs = &scribev1alpha1.ReplicationSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "instance",
+				Namespace: namespace.Name,
+			},
+			Spec: scribev1alpha1.ReplicationSourceSpec{
+				SourcePVC: "mypvc",
+				Trigger: &scribev1alpha1.ReplicationSourceTriggerSpec{
+					Manual: "once",
+				},
+				External: &scribev1alpha1.ReplicationSourceExternalSpec{
+					Provider:   testProviderName,
+					Parameters: map[string]string{"target": "array-2"},
+				},
+			},
+		}
+	})
+	AfterEach(func() {
+		// All resources are namespaced, so this should clean it all up
+		Expect(k8sClient.Delete(ctx, namespace)).To(Succeed())
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, rs)).To(Succeed())
+	})
+
+	It("synchronizes according to the trigger", func() {
+		Eventually(func() string {
+			_ = k8sClient.Get(ctx, types.NamespacedName{Name: rs.Name, Namespace: rs.Namespace}, rs)
+			if rs.Status == nil {
+				return ""
+			}
+			return rs.Status.LastManualSync
+		}, maxWait, interval).Should(Equal("once"))
+		Expect(rs.Status.LastSyncTime).NotTo(BeNil())
+		Expect(rs.Status.External).To(HaveKeyWithValue("target", "array-2"))
+		// The manual trigger has been handled, so there are no more syncs
+		Consistently(func() int {
+			return testSource.syncCount(rs)
+		}, duration, interval).Should(Equal(1))
+	})
+
+	When("the CR names a different provider", func() {
+		BeforeEach(func() {
+			rs.Spec.External.Provider = "example.com/other"
+		})
+		It("is ignored", func() {
+			Consistently(func() *scribev1alpha1.ReplicationSourceStatus {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: rs.Name, Namespace: rs.Namespace},
+					rs)).To(Succeed())
+				return rs.Status
+			}, duration, interval).Should(BeNil())
+			Expect(testSource.syncCount(rs)).To(Equal(0))
+		})
+	})
+})
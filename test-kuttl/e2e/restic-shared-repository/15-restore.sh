@@ -0,0 +1,22 @@
+#! /bin/bash
+
+set -e -o pipefail
+
+# Both sources back up to the same repository, so the source whose data should
+# be restored is selected by its host name
+kubectl create -n "$NAMESPACE" -f - <<EOT
+---
+apiVersion: scribe.backube/v1alpha1
+kind: ReplicationDestination
+metadata:
+  name: restore
+spec:
+  trigger:
+    manual: restore-once
+  restic:
+    repository: restic-repo
+    hostname: ${NAMESPACE}/source-b
+    destinationPVC: data-dest
+    copyMethod: None
+    cacheCapacity: 1Gi
+EOT